@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/adrg/xdg"
+
+	"github.com/verte-zerg/gession/internal/history"
+	"github.com/verte-zerg/gession/internal/tmux"
+)
+
+// ownSwitchInterval is how long after gession records a switch tmux reports it, hook reports within it aren't recorded twice.
+const ownSwitchInterval = 2 * time.Second
+
+// hookActions are the actions recorded for the tmux hooks.
+var hookActions = map[string]history.Action{
+	tmux.HookSessionChanged: history.ActionSwitch,
+	tmux.HookSessionClosed:  history.ActionClose,
+}
+
+func defaultHistoryFile() string {
+	return path.Join(xdg.StateHome, "gession", "history.jsonl")
+}
+
+func runHooks(args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected install or uninstall")
+	}
+
+	switch args[0] {
+	case "install":
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("could not find gession executable: %w", err)
+		}
+
+		return tmux.InstallRecordHooks(executable)
+	case "uninstall":
+		return tmux.UninstallRecordHooks()
+	}
+
+	return fmt.Errorf("unknown command %q, expected install or uninstall", args[0])
+}
+
+func runRecord(args []string) error {
+	flags := flag.NewFlagSet("record", flag.ExitOnError)
+	file := flags.String("f", defaultHistoryFile(), "history file")
+	action := flags.String("a", string(history.ActionSwitch), "recorded action: switch, create or close")
+	directory := flags.String("d", "", "directory of the session")
+	hook := flags.String("hook", "", "tmux hook reporting the action, it's set by `gession hooks install`")
+
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("expected a session name")
+	}
+
+	name := flags.Arg(0)
+	entryAction := history.Action(*action)
+
+	if *hook != "" {
+		hookAction, ok := hookActions[*hook]
+		if !ok {
+			return fmt.Errorf("unknown hook %q", *hook)
+		}
+
+		entryAction = hookAction
+	}
+
+	if entryAction != history.ActionSwitch && entryAction != history.ActionCreate && entryAction != history.ActionClose {
+		return fmt.Errorf("unknown action %q", entryAction)
+	}
+
+	if tmux.IsControlSession(name) {
+		return nil
+	}
+
+	h, err := history.Load(*file)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	// Switches made by gession are recorded before tmux reports them
+	if *hook != "" && entryAction != history.ActionClose && h.IsRecentlyVisited(name, now, ownSwitchInterval) {
+		logger.Info("switch is recorded already", slog.String("name", name))
+
+		return nil
+	}
+
+	// The directory is resolved like in the TUI, so prime folders are found by it
+	if *directory != "" {
+		resolved, err := filepath.EvalSymlinks(*directory)
+		if err != nil {
+			resolved = filepath.Clean(*directory)
+		}
+
+		*directory = resolved
+	}
+
+	return h.Record(history.Entry{
+		Time:      now,
+		Action:    entryAction,
+		Name:      name,
+		Directory: *directory,
+	})
+}
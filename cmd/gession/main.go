@@ -3,17 +3,25 @@ package main
 import (
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
+	"path"
+	"strings"
 
 	"github.com/verte-zerg/gession/internal/event"
 	"github.com/verte-zerg/gession/internal/fsscanner"
+	"github.com/verte-zerg/gession/internal/gitstatus"
+	"github.com/verte-zerg/gession/internal/importer"
 	"github.com/verte-zerg/gession/internal/keyboard"
+	"github.com/verte-zerg/gession/internal/session"
+	"github.com/verte-zerg/gession/internal/tmux"
 	"github.com/verte-zerg/gession/internal/tmux/climode"
 	"github.com/verte-zerg/gession/internal/tmux/commandmode"
 	"github.com/verte-zerg/gession/internal/tui"
 	"github.com/verte-zerg/gession/pkg/assert"
 	"github.com/verte-zerg/gession/pkg/logging"
 
+	"github.com/adrg/xdg"
 	"golang.org/x/term"
 )
 
@@ -22,10 +30,23 @@ var (
 )
 
 type CmdArgs struct {
-	Directory string
-	PrimeDirs []string
-	Legacy    bool
-	Prime     bool
+	Directory        string
+	PrimeRoots       []fsscanner.Root
+	PrimeNameTmpl    string
+	PrimeMarkers     []string
+	PrimeIgnore      []string
+	PrimeFollowLinks bool
+	PrimeWatch       bool
+	PrimeCache       bool
+	PrimeImport      bool
+	Legacy           bool
+	Prime            bool
+	Combined         bool
+}
+
+// IsPrimeListed reports whether prime folders are searched, prime and combined modes list them.
+func (a CmdArgs) IsPrimeListed() bool {
+	return a.Prime || a.Combined
 }
 
 type arrayFlags []string
@@ -44,22 +65,48 @@ func parseArgs() (*CmdArgs, error) {
 	directory := flag.String("d", "", "directory to start a new session (default: \"$HOME\")")
 	legacy := flag.Bool("legacy", false, "use tmux CLI instead of API to get session/buffer list")
 	prime := flag.Bool("prime", false, "prime mode")
+	combined := flag.Bool("combined", false, "combined mode: live sessions and prime folders in one list")
 	primeDirs := arrayFlags{}
-	flag.Var(&primeDirs, "pd", "directories to search for primeagen mode. Can be specified multiple times")
+	flag.Var(&primeDirs, "pd", "directories to search for primeagen mode, `path[:depth]` to search nested folders up to the depth. Can be specified multiple times")
+	primeNameTmpl := flag.String("pn", fsscanner.DefaultNameTemplate, "session name template for prime mode, {name} and {parent} are replaced with the folder and its parent names")
+	primeMarkers := flag.String("pm", strings.Join(fsscanner.DefaultMarkers, ","), "comma-separated files and folders marking a project, prime mode doesn't search inside projects")
+	primeIgnore := arrayFlags{}
+	flag.Var(&primeIgnore, "pi", "gitignore-style pattern of folders to skip in prime mode. Can be specified multiple times")
+	primeFollowLinks := flag.Bool("ps", false, "follow symlinked folders in prime mode")
+	primeCache := flag.Bool("pc", true, "cache found folders in prime mode, so they are shown right away on the next launch")
+	primeImport := flag.Bool("pimport", true, "list tmux-resurrect sessions and tmuxinator projects in prime and combined modes")
+	primeWatch := flag.Bool("pw", false, "watch prime directories and update the list when folders are added or removed (linux only)")
 
 	flag.Parse()
 
-	primeDirsList := make([]string, 0, len(primeDirs))
+	primeRoots := make([]fsscanner.Root, 0, len(primeDirs))
+
+	assert.Assert(!*prime || !*combined, "prime and combined modes can't be used together")
 
 	if *prime {
 		*directory = "/"
+	}
 
+	if *prime || *combined {
 		assert.Assert(len(primeDirs) > 0, "no prime directories specified")
 
 		for _, dir := range primeDirs {
-			_, err := os.Stat(dir)
-			assert.Assert(err == nil, "directory %s does not exist", dir)
-			primeDirsList = append(primeDirsList, dir)
+			root, err := fsscanner.ParseRoot(dir)
+			if err != nil {
+				return nil, fmt.Errorf("invalid prime directory: %w", err)
+			}
+
+			_, err = os.Stat(root.Path)
+			assert.Assert(err == nil, "directory %s does not exist", root.Path)
+			primeRoots = append(primeRoots, root)
+		}
+	}
+
+	markers := make([]string, 0)
+
+	for _, marker := range strings.Split(*primeMarkers, ",") {
+		if marker = strings.TrimSpace(marker); marker != "" {
+			markers = append(markers, marker)
 		}
 	}
 
@@ -73,39 +120,67 @@ func parseArgs() (*CmdArgs, error) {
 	}
 
 	return &CmdArgs{
-		Directory: *directory,
-		PrimeDirs: primeDirsList,
-		Legacy:    *legacy,
-		Prime:     *prime,
+		Directory:        *directory,
+		PrimeRoots:       primeRoots,
+		PrimeNameTmpl:    *primeNameTmpl,
+		PrimeMarkers:     markers,
+		PrimeIgnore:      primeIgnore,
+		PrimeFollowLinks: *primeFollowLinks,
+		PrimeWatch:       *primeWatch,
+		PrimeCache:       *primeCache,
+		PrimeImport:      *primeImport,
+		Legacy:           *legacy,
+		Prime:            *prime,
+		Combined:         *combined,
 	}, nil
 }
 
-func initEventSystem(tuiCP event.ConsumerProducer, tmuxCP event.ConsumerProducer, keyboardP event.Producer, fsscannerCP event.ConsumerProducer) *event.Router {
+func initEventSystem(
+	tuiCP event.ConsumerProducer,
+	tmuxCP event.ConsumerProducer,
+	keyboardP event.Producer,
+	fsscannerCP event.ConsumerProducer,
+	gitstatusCP event.ConsumerProducer,
+) *event.Router {
 	eventSystem := event.New()
 	eventSystem.RegisterConsumer([]event.Type{
 		event.TypeKeyPressed,
 		event.TypeCapturedPane,
 		event.TypeListedTree,
 		event.TypeListedFolders,
+		event.TypeRemovedFolders,
+		event.TypeFoldersFailed,
+		event.TypeCheckedGitStatus,
+		event.TypeCapturedPaneHistory,
+		event.TypeCommandFailed,
+		event.TypeTreeChanged,
+		event.TypeSessionRenamed,
+		event.TypeWindowRenamed,
+		event.TypeWindowClosed,
 	}, tuiCP)
 	eventSystem.RegisterConsumer([]event.Type{
 		event.TypeListTree,
 		event.TypeCapturePane,
+		event.TypeCapturePaneHistory,
 	}, tmuxCP)
 	eventSystem.RegisterConsumer([]event.Type{
 		event.TypeListFolders,
 	}, fsscannerCP)
+	eventSystem.RegisterConsumer([]event.Type{
+		event.TypeCheckGitStatus,
+	}, gitstatusCP)
 	eventSystem.RegisterProducer(tmuxCP)
 	eventSystem.RegisterProducer(tuiCP)
 	eventSystem.RegisterProducer(keyboardP)
 	eventSystem.RegisterProducer(fsscannerCP)
+	eventSystem.RegisterProducer(gitstatusCP)
 
 	eventSystem.Start()
 
 	return eventSystem
 }
 
-func emitInitialEvents(router *event.Router, prime bool, primeDirs []string) {
+func emitInitialEvents(router *event.Router, prime bool, primeRoots []fsscanner.Root) {
 	router.EmitEvent(event.Event{
 		Type: event.TypeListTree,
 	})
@@ -113,18 +188,73 @@ func emitInitialEvents(router *event.Router, prime bool, primeDirs []string) {
 	if prime {
 		router.EmitEvent(event.Event{
 			Type: event.TypeListFolders,
-			Data: primeDirs,
+			Data: primeRoots,
+		})
+	}
+}
+
+// emitImportedSessions sends sessions defined in tmux-resurrect and tmuxinator files as prime folders.
+func emitImportedSessions(router *event.Router) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		logger.Warn("could not determine home directory, sessions aren't imported", slog.Any("error", err))
+
+		return
+	}
+
+	entries, errs := importer.Load(importer.DefaultPaths(homeDir, xdg.DataHome, xdg.ConfigHome))
+
+	sessions := make([]*session.Session, 0, len(entries))
+	for _, entry := range entries {
+		sessions = append(sessions, entry.Session())
+	}
+
+	if len(sessions) > 0 {
+		router.EmitEvent(event.Event{
+			Type: event.TypeListedFolders,
+			Data: event.ListedFolders{
+				Sessions: sessions,
+			},
+		})
+	}
+
+	if len(errs) > 0 {
+		failures := make([]event.FolderError, 0, len(errs))
+		for _, err := range errs {
+			failures = append(failures, event.FolderError{Message: err.Error()})
+		}
+
+		router.EmitEvent(event.Event{
+			Type: event.TypeFoldersFailed,
+			Data: event.FoldersFailed{
+				Errors: failures,
+			},
 		})
 	}
 }
 
-func initTUI(width, height int, tuiKind tui.Kind, directory string) *tui.TUI {
-	tui := tui.NewTUI(width, height, tuiKind, directory)
+func initTUI(width, height int, tuiKind tui.Kind, directory, templatesDir, hibernateDir, trustedPath, historyPath string) *tui.TUI {
+	tui := tui.NewTUI(width, height, tuiKind, directory, templatesDir, hibernateDir, trustedPath, historyPath)
 	tui.Start()
 
 	return tui
 }
 
+// bootstrapTmuxServer starts a tmux server if there is no session to attach to.
+// It returns the name of the created control session or an empty string if the server was already running.
+func bootstrapTmuxServer() string {
+	if tmux.HasSessions() {
+		return ""
+	}
+
+	logger.Info("no tmux sessions found, starting tmux server")
+
+	controlSession, err := tmux.StartServer()
+	assert.Assert(err == nil, "could not start tmux server: %v", err)
+
+	return controlSession
+}
+
 func initTmuxCommandMode() *commandmode.CommandMode {
 	t := commandmode.New()
 	err := t.Start()
@@ -140,13 +270,20 @@ func initTmuxCLIMode() *climode.CLIMode {
 	return t
 }
 
-func initFSScanner() *fsscanner.FSScanner {
-	f := fsscanner.New()
+func initFSScanner(options fsscanner.Options) *fsscanner.FSScanner {
+	f := fsscanner.New(options)
 	f.Start()
 
 	return f
 }
 
+func initGitStatusChecker() *gitstatus.Checker {
+	c := gitstatus.New()
+	c.Start()
+
+	return c
+}
+
 func initKeyboard() *keyboard.Keyboard {
 	keyboard := keyboard.NewKeyboard()
 	keyboard.Start()
@@ -157,6 +294,10 @@ func initKeyboard() *keyboard.Keyboard {
 func main() {
 	logger.Info("starting gession")
 
+	if runSubcommand(os.Args[1:]) {
+		return
+	}
+
 	cmdArgs, err := parseArgs()
 	assert.Assert(err == nil, "could not parse args: %v", err)
 
@@ -168,13 +309,44 @@ func main() {
 	assert.Assert(err == nil, "could not get terminal size: %v", err)
 
 	kind := tui.NormalKind
-	if cmdArgs.Prime {
+
+	switch {
+	case cmdArgs.Prime:
 		kind = tui.PrimeKind
+	case cmdArgs.Combined:
+		kind = tui.CombinedKind
+	}
+
+	controlSession := bootstrapTmuxServer()
+
+	templatesDir := path.Join(xdg.ConfigHome, "gession", "templates")
+	hibernateDir := path.Join(xdg.StateHome, "gession", "hibernated")
+	trustedPath := path.Join(xdg.StateHome, "gession", "trusted.json")
+	historyPath := defaultHistoryFile()
+
+	tui := initTUI(width, height, kind, cmdArgs.Directory, templatesDir, hibernateDir, trustedPath, historyPath)
+	if controlSession != "" {
+		tui.AddExitHook(func() {
+			tmux.StopServer(controlSession)
+		})
+	}
+
+	primeCacheFile := ""
+	if cmdArgs.PrimeCache {
+		primeCacheFile = path.Join(xdg.CacheHome, "gession", "prime.json")
 	}
 
-	tui := initTUI(width, height, kind, cmdArgs.Directory)
-	scanner := initFSScanner()
+	scanner := initFSScanner(fsscanner.Options{
+		NameTemplate:   cmdArgs.PrimeNameTmpl,
+		Markers:        cmdArgs.PrimeMarkers,
+		IgnorePatterns: cmdArgs.PrimeIgnore,
+		FollowSymlinks: cmdArgs.PrimeFollowLinks,
+		Watch:          cmdArgs.PrimeWatch,
+		CacheFile:      primeCacheFile,
+	})
+	gitStatusChecker := initGitStatusChecker()
 	keyboard := initKeyboard()
+	tui.AddExitHook(keyboard.Restore)
 
 	var tmuxInterface event.ConsumerProducer
 
@@ -184,8 +356,12 @@ func main() {
 		tmuxInterface = initTmuxCommandMode()
 	}
 
-	router := initEventSystem(tui, tmuxInterface, keyboard, scanner)
-	emitInitialEvents(router, cmdArgs.Prime, cmdArgs.PrimeDirs)
+	router := initEventSystem(tui, tmuxInterface, keyboard, scanner, gitStatusChecker)
+	emitInitialEvents(router, cmdArgs.IsPrimeListed(), cmdArgs.PrimeRoots)
+
+	if cmdArgs.IsPrimeListed() && cmdArgs.PrimeImport {
+		emitImportedSessions(router)
+	}
 
 	logger.Info("waiting for events")
 	select {}
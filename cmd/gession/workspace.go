@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+
+	"github.com/verte-zerg/gession/internal/workspace"
+)
+
+// subcommands are run instead of the TUI if the first argument is their name.
+var subcommands = map[string]func(args []string) error{
+	"save":    runSave,
+	"restore": runRestore,
+	"hooks":   runHooks,
+	"record":  runRecord,
+}
+
+func defaultWorkspaceFile() string {
+	return path.Join(xdg.StateHome, "gession", "workspace.json")
+}
+
+// runSubcommand runs the subcommand named by the first argument, it returns false if there is no such subcommand.
+func runSubcommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	run, ok := subcommands[args[0]]
+	if !ok {
+		return false
+	}
+
+	err := run(args[1:])
+	if err != nil {
+		logger.Error("subcommand failed", slog.String("subcommand", args[0]), slog.Any("error", err))
+		fmt.Fprintln(os.Stderr, "gession "+args[0]+":", err) //nolint:forbidigo
+		os.Exit(1)
+	}
+
+	return true
+}
+
+func runSave(args []string) error {
+	flags := flag.NewFlagSet("save", flag.ExitOnError)
+	file := flags.String("f", defaultWorkspaceFile(), "workspace file")
+	every := flags.Duration("every", 0, "save periodically with the interval, e.g. 10m, instead of once")
+
+	_ = flags.Parse(args)
+
+	if *every <= 0 {
+		return saveWorkspace(*file)
+	}
+
+	logger.Info("autosave started", slog.String("file", *file), slog.Duration("every", *every))
+
+	for {
+		// The first save is delayed, so autosave started with a new server doesn't overwrite the workspace before it's restored
+		time.Sleep(*every)
+
+		err := saveWorkspace(*file)
+		if err != nil {
+			logger.Warn("autosave failed", slog.Any("error", err))
+		}
+	}
+}
+
+func saveWorkspace(file string) error {
+	saved, err := workspace.Capture()
+	if err != nil {
+		return err
+	}
+
+	err = workspace.Save(file, saved)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("workspace saved", slog.String("file", file), slog.Int("sessions", len(saved.Sessions)))
+
+	return nil
+}
+
+func runRestore(args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	file := flags.String("f", defaultWorkspaceFile(), "workspace file")
+	commands := flags.String("commands", strings.Join(workspace.DefaultRestoreCommands, ","), "comma-separated programs restarted in restored panes")
+	allCommands := flags.Bool("all-commands", false, "restart every saved command, not only the allowed programs")
+
+	_ = flags.Parse(args)
+
+	saved, err := workspace.Load(*file)
+	if err != nil {
+		return err
+	}
+
+	if !*allCommands {
+		saved.FilterCommands(workspace.AllowPrograms(strings.Split(*commands, ",")))
+	}
+
+	restored, skipped, err := workspace.Restore(saved)
+
+	fmt.Printf("restored %d session(s)\n", len(restored)) //nolint:forbidigo
+
+	if len(skipped) > 0 {
+		fmt.Printf("skipped existing session(s): %s\n", strings.Join(skipped, ", ")) //nolint:forbidigo
+	}
+
+	return err
+}
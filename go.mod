@@ -6,6 +6,7 @@ require (
 	github.com/adrg/xdg v0.5.1
 	golang.org/x/term v0.24.0
 	golang.org/x/text v0.18.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
-require golang.org/x/sys v0.26.0 // indirect
+require golang.org/x/sys v0.26.0
@@ -0,0 +1,124 @@
+package contentsearch
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/verte-zerg/gession/pkg/fuzzy"
+)
+
+// Pane is the captured text of a pane, the last lines are the rows visible on the screen.
+type Pane struct {
+	ID          string
+	SessionName string
+	WindowName  string
+	Index       int
+	// Height is the number of visible rows, the rows above are scrolled off to the history.
+	Height int
+	Lines  []string
+
+	lowerLines []string
+}
+
+// Hit is the best matching line of a pane.
+type Hit struct {
+	Pane *Pane
+	// Line is the index of the matched line in the pane lines.
+	Line      int
+	Positions []int
+	Score     int
+}
+
+// ScrollOffset returns how many rows the pane has to be scrolled up to show the hit in the middle,
+// zero if the hit is on the screen already.
+func (h Hit) ScrollOffset() int {
+	rowsBelow := len(h.Pane.Lines) - 1 - h.Line
+	if rowsBelow < h.Pane.Height {
+		return 0
+	}
+
+	// The screen shows the rows from `Height + offset` to `offset` rows above the bottom
+	return rowsBelow + 1 - h.Pane.Height + h.Pane.Height/2 //nolint:mnd
+}
+
+// Index keeps the captured panes in the order they are added.
+type Index struct {
+	panes []*Pane
+	byID  map[string]int
+}
+
+func NewIndex() *Index {
+	return &Index{
+		panes: make([]*Pane, 0),
+		byID:  make(map[string]int),
+	}
+}
+
+// Add indexes the captured content of the pane, a pane added again is replaced.
+func (i *Index) Add(pane Pane, content string) {
+	pane.Lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	pane.lowerLines = make([]string, len(pane.Lines))
+
+	for lineIdx, line := range pane.Lines {
+		pane.Lines[lineIdx] = strings.TrimRightFunc(line, unicode.IsSpace)
+		pane.lowerLines[lineIdx] = strings.ToLower(pane.Lines[lineIdx])
+	}
+
+	if idx, ok := i.byID[pane.ID]; ok {
+		i.panes[idx] = &pane
+
+		return
+	}
+
+	i.byID[pane.ID] = len(i.panes)
+	i.panes = append(i.panes, &pane)
+}
+
+// Len returns the number of indexed panes.
+func (i *Index) Len() int {
+	return len(i.panes)
+}
+
+// Search finds the panes containing the query and returns the best line of every pane, best hits first.
+// The query is matched exactly, ignoring the case unless it has an upper case letter.
+// Lines of equal score are ranked by recency, so the latest output wins.
+func (i *Index) Search(query string) []Hit {
+	if query == "" {
+		return nil
+	}
+
+	lowerQuery := strings.ToLower(query)
+	caseSensitive := lowerQuery != query
+	hits := make([]Hit, 0)
+
+	for _, pane := range i.panes {
+		best := Hit{Pane: pane, Line: -1}
+
+		for lineIdx, line := range pane.Lines {
+			// Lines are filtered by a plain substring search first, only candidates are scored
+			if caseSensitive && !strings.Contains(line, query) || !caseSensitive && !strings.Contains(pane.lowerLines[lineIdx], lowerQuery) {
+				continue
+			}
+
+			result, ok := fuzzy.MatchExact(line, query)
+			if !ok || (best.Line != -1 && result.Score < best.Score) {
+				continue
+			}
+
+			best.Line = lineIdx
+			best.Positions = result.Positions
+			best.Score = result.Score
+		}
+
+		if best.Line != -1 {
+			hits = append(hits, best)
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+
+	return hits
+}
@@ -0,0 +1,74 @@
+package contentsearch_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/verte-zerg/gession/internal/contentsearch"
+)
+
+func TestSearch(t *testing.T) {
+	index := contentsearch.NewIndex()
+	index.Add(contentsearch.Pane{ID: "%1", Height: 2}, "$ make\nerror: build failed\n$ make\nerror: build failed   \n$ \n")
+	index.Add(contentsearch.Pane{ID: "%2", Height: 2}, "Error in test\nok\n")
+	index.Add(contentsearch.Pane{ID: "%3", Height: 2}, "nothing here\n")
+
+	tests := []struct {
+		query     string
+		expected  []string
+		lines     []int
+		positions [][]int
+	}{
+		{query: "", expected: []string{}},
+		{
+			query:     "error",
+			expected:  []string{"%1", "%2"},
+			lines:     []int{3, 0},
+			positions: [][]int{{0, 1, 2, 3, 4}, {0, 1, 2, 3, 4}},
+		},
+		{query: "Error", expected: []string{"%2"}, lines: []int{0}, positions: [][]int{{0, 1, 2, 3, 4}}},
+		{query: "failed", expected: []string{"%1"}, lines: []int{3}, positions: [][]int{{13, 14, 15, 16, 17, 18}}},
+	}
+
+	for _, test := range tests {
+		hits := index.Search(test.query)
+
+		ids := make([]string, 0, len(hits))
+		lines := make([]int, 0, len(hits))
+		positions := make([][]int, 0, len(hits))
+
+		for _, hit := range hits {
+			ids = append(ids, hit.Pane.ID)
+			lines = append(lines, hit.Line)
+			positions = append(positions, hit.Positions)
+		}
+
+		if !reflect.DeepEqual(ids, test.expected) {
+			t.Errorf("Search(%q) panes = %v, expected %v", test.query, ids, test.expected)
+		}
+
+		if len(hits) > 0 && (!reflect.DeepEqual(lines, test.lines) || !reflect.DeepEqual(positions, test.positions)) {
+			t.Errorf("Search(%q) lines = %v %v, expected %v %v", test.query, lines, positions, test.lines, test.positions)
+		}
+	}
+}
+
+func TestScrollOffset(t *testing.T) {
+	pane := &contentsearch.Pane{Height: 4, Lines: make([]string, 20)}
+
+	tests := []struct {
+		line     int
+		expected int
+	}{
+		{line: 19, expected: 0},
+		{line: 16, expected: 0},
+		{line: 15, expected: 3},
+		{line: 0, expected: 18},
+	}
+
+	for _, test := range tests {
+		if offset := (contentsearch.Hit{Pane: pane, Line: test.line}).ScrollOffset(); offset != test.expected {
+			t.Errorf("ScrollOffset(%d) = %d, expected %d", test.line, offset, test.expected)
+		}
+	}
+}
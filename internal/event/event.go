@@ -6,13 +6,29 @@ import (
 )
 
 const (
-	TypeCapturePane   Type = Type("CapturePane")
-	TypeCapturedPane  Type = Type("CapturedPane")
-	TypeListTree      Type = Type("ListTree")
-	TypeListedTree    Type = Type("ListedTree")
-	TypeListFolders   Type = Type("ListFolders")
-	TypeListedFolders Type = Type("ListedFolders")
-	TypeKeyPressed    Type = Type("KeyPressed")
+	TypeCapturePane    Type = Type("CapturePane")
+	TypeCapturedPane   Type = Type("CapturedPane")
+	TypeListTree       Type = Type("ListTree")
+	TypeListedTree     Type = Type("ListedTree")
+	TypeListFolders    Type = Type("ListFolders")
+	TypeListedFolders  Type = Type("ListedFolders")
+	TypeFoldersFailed  Type = Type("FoldersFailed")
+	TypeRemovedFolders Type = Type("RemovedFolders")
+	TypeKeyPressed     Type = Type("KeyPressed")
+	TypeCommandFailed  Type = Type("CommandFailed")
+
+	TypeCheckGitStatus   Type = Type("CheckGitStatus")
+	TypeCheckedGitStatus Type = Type("CheckedGitStatus")
+
+	// Pane history is captured for the content search.
+	TypeCapturePaneHistory  Type = Type("CapturePaneHistory")
+	TypeCapturedPaneHistory Type = Type("CapturedPaneHistory")
+
+	// Control mode notifications.
+	TypeTreeChanged    Type = Type("TreeChanged")
+	TypeSessionRenamed Type = Type("SessionRenamed")
+	TypeWindowRenamed  Type = Type("WindowRenamed")
+	TypeWindowClosed   Type = Type("WindowClosed")
 )
 
 type Event struct {
@@ -29,6 +45,17 @@ type CapturedPane struct {
 	Snapshot string
 }
 
+// CapturePaneHistory asks for the plain text of the pane with the last lines of its history.
+type CapturePaneHistory struct {
+	PaneID string
+	Lines  int
+}
+
+type CapturedPaneHistory struct {
+	PaneID  string
+	Content string
+}
+
 type ListedTree struct {
 	Sessions []*session.Session
 }
@@ -37,11 +64,59 @@ type ListFolders struct {
 	Folders []string
 }
 
+// ListedFolders is a batch of found folders, a folder is sent again when its session name changes.
 type ListedFolders struct {
-	Sessions []*session.Session
+	Sessions   []*session.Session
+	IsComplete bool
+}
+
+// RemovedFolders are IDs of previously listed folders that are gone.
+type RemovedFolders struct {
+	IDs []string
+}
+
+type FolderError struct {
+	Path    string
+	Message string
+}
+
+// FoldersFailed reports folders that couldn't be scanned, the rest of the scan isn't affected.
+type FoldersFailed struct {
+	Errors []FolderError
+}
+
+type CheckGitStatus struct {
+	Directories []string
+}
+
+// CheckedGitStatus is a batch of checked directories, the status is nil if a directory is not a git repository.
+// Errors are messages of directories that couldn't be checked, their status is nil too.
+type CheckedGitStatus struct {
+	Statuses map[string]*session.GitStatus
+	Errors   map[string]string
 }
 
 type KeyPressed struct {
 	SpecialKey key.Special
 	Key        rune
 }
+
+type CommandFailed struct {
+	Request Type
+	Command string
+	Message string
+}
+
+type SessionRenamed struct {
+	SessionID string
+	Name      string
+}
+
+type WindowRenamed struct {
+	WindowID string
+	Name     string
+}
+
+type WindowClosed struct {
+	WindowID string
+}
@@ -1,6 +1,8 @@
 package event
 
 import (
+	"log/slog"
+
 	"github.com/verte-zerg/gession/pkg/logging"
 )
 
@@ -73,7 +75,9 @@ func (e *Router) proxyEvents() {
 		outputChs, ok := e.outputChs[event.Type]
 
 		if !ok {
-			return
+			logger.Warn("no consumers for event", slog.String("type", string(event.Type)))
+
+			continue
 		}
 
 		for _, outputCh := range outputChs {
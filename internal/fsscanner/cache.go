@@ -0,0 +1,171 @@
+package fsscanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	cacheVersion  = 1
+	cacheFileMode = 0o644
+	cacheDirMode  = 0o755
+)
+
+// cacheFile is the scan result of the roots, it's valid while the scan options and the ignore files are the same.
+type cacheFile struct {
+	Version    int                       `json:"version"`
+	Key        string                    `json:"key"`
+	Folders    []string                  `json:"folders"`
+	Containers map[string]cacheContainer `json:"containers"`
+}
+
+type cacheContainer struct {
+	Root    Root      `json:"root"`
+	Depth   int       `json:"depth"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// cacheKey identifies everything the scan result depends on except the folders themselves.
+func cacheKey(roots []Root, options Options) string {
+	ignoreFiles := make([][]string, 0, len(roots))
+	for _, root := range roots {
+		ignoreFiles = append(ignoreFiles, readIgnoreFile(filepath.Join(root.Path, IgnoreFileName)))
+	}
+
+	data, err := json.Marshal([]any{roots, options.Markers, options.IgnorePatterns, options.FollowSymlinks, ignoreFiles})
+	if err != nil {
+		return ""
+	}
+
+	hash := sha256.Sum256(data)
+
+	return hex.EncodeToString(hash[:])
+}
+
+// restoreCache sends the cached folders and remembers their containers.
+// It returns false if there is no valid cache for the roots.
+func (t *FSScanner) restoreCache(roots []Root) bool {
+	if t.options.CacheFile == "" {
+		return false
+	}
+
+	data, err := os.ReadFile(t.options.CacheFile)
+	if err != nil {
+		logger.Info("could not read cache", slog.Any("error", err))
+
+		return false
+	}
+
+	cache := cacheFile{}
+
+	err = json.Unmarshal(data, &cache)
+	if err != nil || cache.Version != cacheVersion || cache.Key != cacheKey(roots, t.options) {
+		logger.Info("cache is outdated", slog.String("file", t.options.CacheFile))
+
+		return false
+	}
+
+	for containerPath, container := range cache.Containers {
+		t.addContainer(scanResult{folder: containerPath, root: container.Root, depth: container.Depth, modTime: container.ModTime})
+	}
+
+	// Roots that couldn't be read before have no modification time, so they are always checked
+	for _, root := range roots {
+		if _, ok := t.containers[root.Path]; !ok {
+			t.addContainer(scanResult{folder: root.Path, root: root})
+		}
+	}
+
+	logger.Info("restored cache", slog.Int("folders", len(cache.Folders)), slog.Int("containers", len(cache.Containers)))
+
+	t.flush(cache.Folders, true)
+
+	return true
+}
+
+// changedContainers returns containers modified since they were read, entries were added or removed in them.
+func (t *FSScanner) changedContainers() map[string]struct{} {
+	changed := make(map[string]struct{})
+
+	for containerPath, container := range t.containers {
+		info, err := os.Stat(containerPath)
+		if err != nil || !info.ModTime().Equal(container.modTime) {
+			changed[containerPath] = struct{}{}
+		}
+	}
+
+	return changed
+}
+
+// saveCache writes the found folders, so the next launch shows them before checking for changes.
+func (t *FSScanner) saveCache() {
+	if t.options.CacheFile == "" {
+		return
+	}
+
+	cache := cacheFile{
+		Version:    cacheVersion,
+		Key:        cacheKey(t.roots, t.options),
+		Folders:    make([]string, 0, len(t.folderNames)),
+		Containers: make(map[string]cacheContainer, len(t.containers)),
+	}
+
+	for folderPath := range t.folderNames {
+		cache.Folders = append(cache.Folders, folderPath)
+	}
+
+	for containerPath, container := range t.containers {
+		cache.Containers[containerPath] = cacheContainer{Root: container.root, Depth: container.depth, ModTime: container.modTime}
+	}
+
+	err := writeCache(t.options.CacheFile, cache)
+	if err != nil {
+		logger.Warn("could not save cache", slog.Any("error", err))
+	}
+}
+
+// writeCache replaces the cache file atomically, so concurrent launches never read a partial file.
+func writeCache(cachePath string, cache cacheFile) error {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("could not encode cache: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(cachePath), cacheDirMode)
+	if err != nil {
+		return fmt.Errorf("could not create cache directory: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(cachePath), filepath.Base(cachePath)+".*")
+	if err != nil {
+		return fmt.Errorf("could not create cache file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not write cache file: %w", err)
+	}
+
+	err = os.Chmod(tmpFile.Name(), cacheFileMode)
+	if err != nil {
+		return fmt.Errorf("could not write cache file: %w", err)
+	}
+
+	err = os.Rename(tmpFile.Name(), cachePath)
+	if err != nil {
+		return fmt.Errorf("could not replace cache file: %w", err)
+	}
+
+	return nil
+}
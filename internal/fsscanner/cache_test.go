@@ -0,0 +1,74 @@
+package fsscanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/verte-zerg/gession/internal/event"
+)
+
+func TestCache(t *testing.T) {
+	root := t.TempDir()
+	cacheFile := filepath.Join(t.TempDir(), "prime.json")
+
+	for _, dir := range []string{"org/api", "org/web", "solo/.git"} {
+		err := os.MkdirAll(filepath.Join(root, dir), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	roots := []Root{{Path: root, MaxDepth: 2}}
+
+	listFolders := func() []event.Event {
+		scanner := New(Options{CacheFile: cacheFile})
+		outputCh := make(chan event.Event, event.MaxQueue)
+		scanner.SetOutputCh(outputCh)
+		scanner.listFolders(roots)
+		close(outputCh)
+
+		events := make([]event.Event, 0)
+		for e := range outputCh {
+			events = append(events, e)
+		}
+
+		return events
+	}
+
+	listFolders()
+
+	// Modification times of folders created within the same clock tick may be equal
+	time.Sleep(10 * time.Millisecond)
+
+	err := os.Remove(filepath.Join(root, "org", "web"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Mkdir(filepath.Join(root, "org", "cli"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := listFolders()
+	if len(events) != 3 {
+		t.Fatalf("Expected cached, removed and added folders events, got %+v", events)
+	}
+
+	cached, _ := events[0].Data.(event.ListedFolders)
+	if len(cached.Sessions) != 3 {
+		t.Errorf("Expected 3 cached folders, got %d", len(cached.Sessions))
+	}
+
+	removed, _ := events[1].Data.(event.RemovedFolders)
+	if len(removed.IDs) != 1 || removed.IDs[0] != folderID(filepath.Join(root, "org", "web")) {
+		t.Errorf("Expected the web folder to be removed, got %+v", removed.IDs)
+	}
+
+	added, _ := events[2].Data.(event.ListedFolders)
+	if len(added.Sessions) != 1 || added.Sessions[0].Directory != filepath.Join(root, "org", "cli") {
+		t.Errorf("Expected the cli folder to be added, got %+v", added.Sessions)
+	}
+}
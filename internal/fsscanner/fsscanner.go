@@ -1,11 +1,9 @@
 package fsscanner
 
 import (
-	"os"
-	"path"
-	"slices"
+	"log/slog"
 	"sort"
-	"strings"
+	"time"
 
 	"github.com/verte-zerg/gession/internal/event"
 	"github.com/verte-zerg/gession/internal/session"
@@ -13,20 +11,81 @@ import (
 	"github.com/verte-zerg/gession/pkg/logging"
 )
 
+const (
+	// flushInterval is how often found folders are sent while scanning.
+	flushInterval = 50 * time.Millisecond
+	// maxBatchSize is the number of found folders sent without waiting for the flush interval.
+	maxBatchSize = 256
+)
+
 var (
 	logger = logging.GetInstance().WithGroup("fsscanner")
 )
 
+type Options struct {
+	// NameTemplate renders session names, `{name}` and `{parent}` are replaced with the folder and its parent names.
+	NameTemplate string
+	// Markers are files and folders marking a project folder, the scan doesn't descend into projects.
+	Markers []string
+	// IgnorePatterns are .gitignore-style patterns of folders to skip, combined with the ignore file of each root.
+	IgnorePatterns []string
+	// FollowSymlinks makes the scan descend into symlinked folders, every real folder is visited once.
+	FollowSymlinks bool
+	// Workers is the number of folders read concurrently.
+	Workers int
+	// Watch keeps the found folders up to date by watching the folders the scan descended into.
+	Watch bool
+	// CacheFile stores found folders between launches, they are shown before checking for changes. Empty disables it.
+	CacheFile string
+}
+
 type FSScanner struct {
+	options Options
+	roots   []Root
+
+	// folderNames are session names of found folders by their paths.
+	folderNames map[string]string
+
+	// containers are folders the scan descended into by their paths, their entries decide which projects are found.
+	containers map[string]containerFolder
+
+	watcher folderWatcher
+
 	inputEventCh  chan event.Event
 	outputEventCh chan event.Event
 }
 
-func New() *FSScanner {
-	return &FSScanner{
+func New(options Options) *FSScanner {
+	if options.NameTemplate == "" {
+		options.NameTemplate = DefaultNameTemplate
+	}
+
+	if options.Markers == nil {
+		options.Markers = DefaultMarkers
+	}
+
+	if options.Workers == 0 {
+		options.Workers = DefaultWorkers
+	}
+
+	scanner := &FSScanner{
+		options:       options,
+		folderNames:   make(map[string]string),
+		containers:    make(map[string]containerFolder),
 		inputEventCh:  make(chan event.Event, event.MaxQueue),
 		outputEventCh: make(chan event.Event, event.MaxQueue),
 	}
+
+	if options.Watch {
+		watcher, err := newWatcher()
+		if err != nil {
+			logger.Warn("could not watch folders", slog.Any("error", err))
+		} else {
+			scanner.watcher = watcher
+		}
+	}
+
+	return scanner
 }
 
 func (t *FSScanner) Start() {
@@ -44,63 +103,175 @@ func (t *FSScanner) SetOutputCh(outputCh chan event.Event) {
 }
 
 func (t *FSScanner) handler() {
+	changedFolders := make(map[string]struct{})
+
+	var rescanTimer <-chan time.Time
+
+	for {
+		select {
+		case e := <-t.inputEventCh:
+			assert.Assert(e.Type == event.TypeListFolders, "fsscanner supports only list folders event")
+
+			roots, ok := e.Data.([]Root)
+			assert.Assert(ok, "data should be a list of roots")
+
+			t.listFolders(roots)
+		case folder := <-t.watcherChanges():
+			changedFolders[folder] = struct{}{}
+			rescanTimer = time.After(rescanDelay)
+		case <-rescanTimer:
+			t.rescan(changedFolders)
+			t.saveCache()
+
+			changedFolders = make(map[string]struct{})
+			rescanTimer = nil
+		}
+	}
+}
+
+// listFolders sends folders of the roots. Cached folders are sent right away and corrected after checking for changes.
+func (t *FSScanner) listFolders(roots []Root) {
+	t.roots = roots
+
+	if t.restoreCache(roots) {
+		t.rescan(t.changedContainers())
+	} else {
+		t.scanRoots(roots)
+	}
+
+	t.saveCache()
+}
+
+// scanRoots scans the roots and sends found folders in batches as they are found.
+// Folders that couldn't be read are reported after the scan.
+func (t *FSScanner) scanRoots(roots []Root) {
+	results := make(chan scanResult, event.MaxQueue)
+	go scan(newRootTasks(roots, t.options), t.options.Workers, results)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]string, 0)
+	failures := make([]event.FolderError, 0)
+
 	for {
-		e := <-t.inputEventCh
+		select {
+		case result, ok := <-results:
+			if !ok {
+				t.flush(batch, true)
+				t.reportFailures(failures)
+
+				return
+			}
+
+			if result.err != nil {
+				logger.Warn("could not scan folder", slog.String("folder", result.folder), slog.Any("error", result.err))
+				failures = append(failures, event.FolderError{Path: result.folder, Message: result.err.Error()})
 
-		assert.Assert(e.Type == event.TypeListFolders, "fsscanner supports only list folders event")
+				continue
+			}
 
-		folders, ok := e.Data.([]string)
-		assert.Assert(ok, "data should be a list of strings")
+			if !result.isProject {
+				t.addContainer(result)
 
-		pathsMap := make(map[string]struct{})
+				continue
+			}
 
-		slices.Reverse(folders)
+			batch = append(batch, result.folder)
 
-		for _, folder := range folders {
-			for _, subfolder := range listFolder(folder) {
-				pathsMap[subfolder] = struct{}{}
+			if len(batch) >= maxBatchSize {
+				t.flush(batch, false)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				t.flush(batch, false)
+				batch = batch[:0]
 			}
 		}
+	}
+}
 
-		sessions := make([]*session.Session, 0, len(pathsMap))
-		for subfolder := range pathsMap {
-			sessions = append(sessions, convertFolderToSession(subfolder))
+// flush adds the folders to the found ones and sends sessions of the new folders.
+// Names are resolved over all found folders, so folders whose names changed because of the new ones are sent too.
+func (t *FSScanner) flush(folders []string, isComplete bool) {
+	for _, folder := range folders {
+		if _, ok := t.folderNames[folder]; !ok {
+			t.folderNames[folder] = ""
 		}
+	}
+
+	folderPaths := make([]string, 0, len(t.folderNames))
+	for folderPath := range t.folderNames {
+		folderPaths = append(folderPaths, folderPath)
+	}
 
-		sort.Slice(sessions, func(i, j int) bool {
-			return sessions[i].Name > sessions[j].Name
-		})
+	names := nameFolders(folderPaths, t.options.NameTemplate)
 
-		t.outputEventCh <- event.Event{
-			Type: event.TypeListedFolders,
-			Data: sessions,
+	sessions := make([]*session.Session, 0, len(folders))
+
+	for folderPath, name := range names {
+		if t.folderNames[folderPath] != name {
+			sessions = append(sessions, convertFolderToSession(folderPath, name))
 		}
 	}
-}
 
-func listFolder(folder string) []string {
-	entities, err := os.ReadDir(folder)
-	assert.Assert(err == nil, "could not read directory")
+	t.folderNames = names
 
-	files := make([]string, 0)
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Name > sessions[j].Name
+	})
 
-	for _, entity := range entities {
-		if entity.IsDir() {
-			files = append(files, path.Join(folder, entity.Name()))
-		}
+	logger.Info("listed folders", slog.Int("count", len(sessions)), slog.Bool("complete", isComplete))
+
+	t.outputEventCh <- event.Event{
+		Type: event.TypeListedFolders,
+		Data: event.ListedFolders{
+			Sessions:   sessions,
+			IsComplete: isComplete,
+		},
 	}
+}
 
-	return files
+type containerFolder struct {
+	root    Root
+	depth   int
+	modTime time.Time
 }
 
-func convertFolderToSession(folderPath string) *session.Session {
-	dirname := path.Base(folderPath)
+func (t *FSScanner) addContainer(result scanResult) {
+	if _, ok := t.containers[result.folder]; !ok {
+		t.watch(result.folder)
+	}
 
-	normalizedDirname := strings.ReplaceAll(dirname, ".", "_")
+	t.containers[result.folder] = containerFolder{root: result.root, depth: result.depth, modTime: result.modTime}
+}
 
+func (t *FSScanner) reportFailures(failures []event.FolderError) {
+	if len(failures) == 0 {
+		return
+	}
+
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].Path < failures[j].Path
+	})
+
+	t.outputEventCh <- event.Event{
+		Type: event.TypeFoldersFailed,
+		Data: event.FoldersFailed{
+			Errors: failures,
+		},
+	}
+}
+
+func convertFolderToSession(folderPath, name string) *session.Session {
 	return &session.Session{
-		ID:        "notexisted_" + dirname,
-		Name:      normalizedDirname,
+		ID:        folderID(folderPath),
+		Name:      name,
 		Directory: folderPath,
 	}
 }
+
+func folderID(folderPath string) string {
+	return "notexisted_" + folderPath
+}
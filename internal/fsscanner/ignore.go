@@ -0,0 +1,160 @@
+package fsscanner
+
+import (
+	"bufio"
+	"log/slog"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	// IgnoreFileName is a file in a prime root with .gitignore-style patterns of folders to skip.
+	IgnoreFileName = ".gessionignore"
+)
+
+type ignoreRule struct {
+	regexp   *regexp.Regexp
+	negate   bool
+	anchored bool
+}
+
+// ignoreMatcher matches folder paths relative to a root against .gitignore-style patterns.
+// Supported syntax: comments, `!` negation, leading `/` and inner `/` anchoring, trailing `/`, `*`, `?`, `**` and `[...]`.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+func newIgnoreMatcher(patterns []string) *ignoreMatcher {
+	matcher := &ignoreMatcher{}
+
+	for _, pattern := range patterns {
+		if rule, ok := parseIgnoreRule(pattern); ok {
+			matcher.rules = append(matcher.rules, rule)
+		}
+	}
+
+	return matcher
+}
+
+// readIgnoreFile reads patterns from the ignore file, a missing file has no patterns.
+func readIgnoreFile(filePath string) []string {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	patterns := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+
+	return patterns
+}
+
+func parseIgnoreRule(pattern string) (ignoreRule, bool) {
+	pattern = strings.TrimRight(pattern, " \t\r")
+
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{}
+
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\`) {
+		pattern = pattern[1:]
+	}
+
+	// Only folders are matched, so the folder-only marker changes nothing
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	if strings.Contains(pattern, "/") {
+		rule.anchored = true
+		pattern = strings.TrimPrefix(pattern, "/")
+	}
+
+	if pattern == "" {
+		return ignoreRule{}, false
+	}
+
+	re, err := regexp.Compile("^" + globToRegexp(pattern) + "$")
+	if err != nil {
+		logger.Warn("invalid ignore pattern", slog.String("pattern", pattern), slog.Any("error", err))
+
+		return ignoreRule{}, false
+	}
+
+	rule.regexp = re
+
+	return rule, true
+}
+
+func globToRegexp(pattern string) string {
+	builder := strings.Builder{}
+
+	for i := 0; i < len(pattern); i++ {
+		switch char := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			builder.WriteString("(.*/)?")
+
+			i += 2
+		case strings.HasPrefix(pattern[i:], "/**") && i+3 == len(pattern):
+			builder.WriteString("(/.*)?")
+
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			builder.WriteString(".*")
+
+			i++
+		case char == '*':
+			builder.WriteString("[^/]*")
+		case char == '?':
+			builder.WriteString("[^/]")
+		case char == '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end == -1 {
+				builder.WriteString(`\[`)
+
+				continue
+			}
+
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			builder.WriteString("[" + class + "]")
+
+			i += end
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+
+	return builder.String()
+}
+
+// isIgnored reports whether the folder is ignored, the last matching pattern wins.
+func (m *ignoreMatcher) isIgnored(relPath string) bool {
+	isIgnored := false
+
+	for _, rule := range m.rules {
+		subject := path.Base(relPath)
+		if rule.anchored {
+			subject = relPath
+		}
+
+		if rule.regexp.MatchString(subject) {
+			isIgnored = !rule.negate
+		}
+	}
+
+	return isIgnored
+}
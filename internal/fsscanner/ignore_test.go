@@ -0,0 +1,97 @@
+package fsscanner
+
+import (
+	"testing"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		expected bool
+	}{
+		{
+			name:     "Name matches on any level",
+			patterns: []string{"node_modules"},
+			path:     "web/app/node_modules",
+			expected: true,
+		},
+		{
+			name:     "Trailing slash",
+			patterns: []string{"vendor/"},
+			path:     "api/vendor",
+			expected: true,
+		},
+		{
+			name:     "Leading slash anchors to root",
+			patterns: []string{"/archive"},
+			path:     "work/archive",
+			expected: false,
+		},
+		{
+			name:     "Inner slash anchors to root",
+			patterns: []string{"work/old-*"},
+			path:     "work/old-api",
+			expected: true,
+		},
+		{
+			name:     "Star does not cross folders",
+			patterns: []string{"work/*"},
+			path:     "work/org/repo",
+			expected: false,
+		},
+		{
+			name:     "Double star crosses folders",
+			patterns: []string{"work/**/tmp"},
+			path:     "work/org/repo/tmp",
+			expected: true,
+		},
+		{
+			name:     "Leading double star",
+			patterns: []string{"**/build"},
+			path:     "build",
+			expected: true,
+		},
+		{
+			name:     "Negation wins when last",
+			patterns: []string{"*-old", "!keep-old"},
+			path:     "keep-old",
+			expected: false,
+		},
+		{
+			name:     "Character class",
+			patterns: []string{"tmp[0-9]"},
+			path:     "tmp1",
+			expected: true,
+		},
+		{
+			name:     "Negated character class",
+			patterns: []string{"tmp[!0-9]"},
+			path:     "tmp1",
+			expected: false,
+		},
+		{
+			name:     "Comments and empty lines are skipped",
+			patterns: []string{"# api", "", "  "},
+			path:     "api",
+			expected: false,
+		},
+		{
+			name:     "Escaped hash",
+			patterns: []string{`\#draft`},
+			path:     "#draft",
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := newIgnoreMatcher(tt.patterns)
+
+			if isIgnored := matcher.isIgnored(tt.path); isIgnored != tt.expected {
+				t.Errorf("Expected `%s` ignored to be %t with %q, got %t", tt.path, tt.expected, tt.patterns, isIgnored)
+			}
+		})
+	}
+}
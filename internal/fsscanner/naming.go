@@ -0,0 +1,85 @@
+package fsscanner
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/verte-zerg/gession/internal/session"
+)
+
+const (
+	// DefaultNameTemplate names a session after its folder.
+	DefaultNameTemplate = "{name}"
+
+	namePlaceholder   = "{name}"
+	parentPlaceholder = "{parent}"
+)
+
+// nameFolders renders session names for the folders with the template.
+// Folders with conflicting names get parent folders prepended one by one until the names are unique,
+// e.g. `~/work/api` and `~/oss/api` are named `work/api` and `oss/api`.
+func nameFolders(folderPaths []string, template string) map[string]string {
+	sortedPaths := make([]string, len(folderPaths))
+	copy(sortedPaths, folderPaths)
+	sort.Strings(sortedPaths)
+
+	prefixSegments := make(map[string]int, len(sortedPaths))
+	names := make(map[string]string, len(sortedPaths))
+
+	for {
+		pathsByName := make(map[string][]string)
+
+		for _, folderPath := range sortedPaths {
+			names[folderPath] = renderName(folderPath, template, prefixSegments[folderPath])
+			pathsByName[names[folderPath]] = append(pathsByName[names[folderPath]], folderPath)
+		}
+
+		isChanged := false
+
+		for _, conflictingPaths := range pathsByName {
+			if len(conflictingPaths) < 2 { //nolint:mnd
+				continue
+			}
+
+			for _, folderPath := range conflictingPaths {
+				if prefixSegments[folderPath] < len(parentSegments(folderPath)) {
+					prefixSegments[folderPath]++
+					isChanged = true
+				}
+			}
+		}
+
+		if !isChanged {
+			return names
+		}
+	}
+}
+
+// renderName renders the template for the folder and prepends the given number of parent folders.
+func renderName(folderPath, template string, prefixCount int) string {
+	parents := parentSegments(folderPath)
+
+	parent := ""
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	}
+
+	name := strings.NewReplacer(
+		namePlaceholder, filepath.Base(folderPath),
+		parentPlaceholder, parent,
+	).Replace(template)
+
+	prefix := parents[len(parents)-prefixCount:]
+
+	return session.SanitizeName(strings.Join(append(prefix, name), "/"))
+}
+
+func parentSegments(folderPath string) []string {
+	dir := strings.Trim(filepath.Dir(filepath.Clean(folderPath)), string(filepath.Separator))
+	if dir == "" || dir == "." {
+		return []string{}
+	}
+
+	return strings.Split(dir, string(filepath.Separator))
+}
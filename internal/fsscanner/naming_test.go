@@ -0,0 +1,73 @@
+package fsscanner
+
+import (
+	"testing"
+)
+
+func TestNameFolders(t *testing.T) {
+	tests := []struct {
+		name     string
+		paths    []string
+		template string
+		expected map[string]string
+	}{
+		{
+			name:     "Unique basenames",
+			paths:    []string{"/home/user/work/api", "/home/user/oss/web"},
+			template: DefaultNameTemplate,
+			expected: map[string]string{
+				"/home/user/work/api": "api",
+				"/home/user/oss/web":  "web",
+			},
+		},
+		{
+			name:     "Same basenames get parents",
+			paths:    []string{"/home/user/work/api", "/home/user/oss/api", "/home/user/oss/web"},
+			template: DefaultNameTemplate,
+			expected: map[string]string{
+				"/home/user/work/api": "work/api",
+				"/home/user/oss/api":  "oss/api",
+				"/home/user/oss/web":  "web",
+			},
+		},
+		{
+			name:     "Same parents get more segments",
+			paths:    []string{"/a/x/src/api", "/b/x/src/api"},
+			template: DefaultNameTemplate,
+			expected: map[string]string{
+				"/a/x/src/api": "a/x/src/api",
+				"/b/x/src/api": "b/x/src/api",
+			},
+		},
+		{
+			name:     "Dots are replaced",
+			paths:    []string{"/srv/example.com", "/srv/host:8080"},
+			template: DefaultNameTemplate,
+			expected: map[string]string{
+				"/srv/example.com": "example_com",
+				"/srv/host:8080":   "host_8080",
+			},
+		},
+		{
+			name:     "Template with parent",
+			paths:    []string{"/code/org/repo", "/code/team/repo"},
+			template: "{parent}-{name}",
+			expected: map[string]string{
+				"/code/org/repo":  "org-repo",
+				"/code/team/repo": "team-repo",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := nameFolders(tt.paths, tt.template)
+
+			for folderPath, expectedName := range tt.expected {
+				if names[folderPath] != expectedName {
+					t.Errorf("Expected name of `%s` to be `%s`, got `%s`", folderPath, expectedName, names[folderPath])
+				}
+			}
+		})
+	}
+}
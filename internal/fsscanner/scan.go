@@ -0,0 +1,137 @@
+package fsscanner
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWorkers is the number of folders read concurrently.
+	DefaultWorkers = 8
+)
+
+// scanResult is a visited folder: a project, a folder the scan descended into or a folder that couldn't be read.
+type scanResult struct {
+	folder    string
+	root      Root
+	depth     int
+	isProject bool
+	modTime   time.Time
+	err       error
+}
+
+type scanTask struct {
+	walker *walker
+	folder string
+	depth  int
+}
+
+// taskQueue is an unbounded queue of folders to visit.
+// Workers add subfolders while visiting, so the queue is done when it's empty and no task is being visited.
+type taskQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	tasks   []scanTask
+	pending int
+}
+
+func newTaskQueue() *taskQueue {
+	queue := &taskQueue{}
+	queue.cond = sync.NewCond(&queue.mu)
+
+	return queue
+}
+
+func (q *taskQueue) push(task scanTask) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.tasks = append(q.tasks, task)
+	q.pending++
+	q.cond.Signal()
+}
+
+// pop waits for a task, it returns false when all tasks are done.
+func (q *taskQueue) pop() (scanTask, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.tasks) == 0 && q.pending > 0 {
+		q.cond.Wait()
+	}
+
+	if len(q.tasks) == 0 {
+		return scanTask{}, false
+	}
+
+	task := q.tasks[0]
+	q.tasks = q.tasks[1:]
+
+	return task, true
+}
+
+// done marks a popped task as visited.
+func (q *taskQueue) done() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pending--
+	if q.pending == 0 {
+		q.cond.Broadcast()
+	}
+}
+
+func newRootTasks(roots []Root, options Options) []scanTask {
+	tasks := make([]scanTask, 0, len(roots))
+
+	for _, root := range roots {
+		tasks = append(tasks, scanTask{walker: newWalker(root, options), folder: root.Path})
+	}
+
+	return tasks
+}
+
+// scan walks folders of the tasks with a pool of workers and sends results as they are found.
+// The results channel is closed when the scan is finished.
+func scan(tasks []scanTask, workerCount int, results chan<- scanResult) {
+	queue := newTaskQueue()
+
+	for _, task := range tasks {
+		queue.push(task)
+	}
+
+	workers := sync.WaitGroup{}
+
+	for range max(1, workerCount) {
+		workers.Add(1)
+
+		go func() {
+			defer workers.Done()
+
+			for task, ok := queue.pop(); ok; task, ok = queue.pop() {
+				visitTask(queue, task, results)
+				queue.done()
+			}
+		}()
+	}
+
+	workers.Wait()
+	close(results)
+}
+
+func visitTask(queue *taskQueue, task scanTask, results chan<- scanResult) {
+	visit, err := task.walker.visit(task.folder, task.depth)
+
+	results <- scanResult{
+		folder:    task.folder,
+		root:      task.walker.root,
+		depth:     task.depth,
+		isProject: visit.isProject,
+		modTime:   visit.modTime,
+		err:       err,
+	}
+
+	for _, subfolder := range visit.subfolders {
+		queue.push(scanTask{walker: task.walker, folder: subfolder, depth: task.depth + 1})
+	}
+}
@@ -0,0 +1,197 @@
+package fsscanner
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxDepth lists only direct children of a root.
+	DefaultMaxDepth = 1
+)
+
+// DefaultMarkers are files and folders marking a project folder.
+var DefaultMarkers = []string{".git", "go.mod", "package.json"}
+
+// Root is a folder to search for projects in.
+type Root struct {
+	Path     string
+	MaxDepth int
+}
+
+// ParseRoot parses a root from `path[:depth]`.
+func ParseRoot(spec string) (Root, error) {
+	root := Root{Path: spec, MaxDepth: DefaultMaxDepth}
+
+	if idx := strings.LastIndex(spec, ":"); idx != -1 {
+		if depth, err := strconv.Atoi(spec[idx+1:]); err == nil {
+			if depth < 1 {
+				return Root{}, fmt.Errorf("depth of %s should be positive", spec)
+			}
+
+			root.Path = spec[:idx]
+			root.MaxDepth = depth
+		}
+	}
+
+	root.Path = filepath.Clean(root.Path)
+
+	return root, nil
+}
+
+// walker finds project folders in a root, it's safe to visit folders of the root concurrently.
+// A folder is a project if it has a marker, it's on the max depth or it has no folders to descend into.
+// Projects are never descended into.
+type walker struct {
+	root    Root
+	options Options
+	ignore  *ignoreMatcher
+
+	visitedMu    sync.Mutex
+	visitedPaths map[string]struct{}
+}
+
+func newWalker(root Root, options Options) *walker {
+	patterns := append([]string{}, options.IgnorePatterns...)
+	patterns = append(patterns, readIgnoreFile(filepath.Join(root.Path, IgnoreFileName))...)
+
+	walker := &walker{
+		root:         root,
+		options:      options,
+		ignore:       newIgnoreMatcher(patterns),
+		visitedPaths: make(map[string]struct{}),
+	}
+	walker.markVisited(root.Path)
+
+	return walker
+}
+
+// folderVisit is a classified folder: a project or a folder to descend into.
+type folderVisit struct {
+	isProject  bool
+	subfolders []string
+	// modTime is the modification time of a read folder, it changes when entries are added or removed.
+	modTime time.Time
+}
+
+// visit classifies the folder on the depth, the root is on depth 0.
+func (w *walker) visit(folder string, depth int) (folderVisit, error) {
+	isRoot := depth == 0
+
+	if !isRoot && depth >= w.root.MaxDepth {
+		return folderVisit{isProject: true}, nil
+	}
+
+	// The folder is stated before reading, so changes made while reading are noticed later
+	info, err := os.Stat(folder)
+	if err != nil {
+		return folderVisit{}, fmt.Errorf("could not stat directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		return folderVisit{}, fmt.Errorf("could not read directory: %w", err)
+	}
+
+	if !isRoot {
+		for _, entry := range entries {
+			if w.isMarker(entry.Name()) {
+				return folderVisit{isProject: true}, nil
+			}
+		}
+	}
+
+	subfolders := w.filterSubfolders(folder, entries)
+
+	return folderVisit{
+		isProject:  !isRoot && len(subfolders) == 0,
+		subfolders: subfolders,
+		modTime:    info.ModTime(),
+	}, nil
+}
+
+// filterSubfolders returns folders to visit: not hidden, not ignored and not visited yet if symlinks are followed.
+func (w *walker) filterSubfolders(folder string, entries []os.DirEntry) []string {
+	subfolders := make([]string, 0)
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		subfolder := filepath.Join(folder, entry.Name())
+
+		if !w.isFolder(subfolder, entry) {
+			continue
+		}
+
+		relPath, err := filepath.Rel(w.root.Path, subfolder)
+		if err == nil && w.ignore.isIgnored(filepath.ToSlash(relPath)) {
+			continue
+		}
+
+		if w.options.FollowSymlinks && !w.markVisited(subfolder) {
+			logger.Info("skip visited folder", slog.String("folder", subfolder))
+
+			continue
+		}
+
+		subfolders = append(subfolders, subfolder)
+	}
+
+	return subfolders
+}
+
+func (w *walker) isFolder(folder string, entry os.DirEntry) bool {
+	if entry.IsDir() {
+		return true
+	}
+
+	if entry.Type()&os.ModeSymlink == 0 || !w.options.FollowSymlinks {
+		return false
+	}
+
+	info, err := os.Stat(folder)
+
+	return err == nil && info.IsDir()
+}
+
+// markVisited remembers the real path of the folder, so symlink loops are visited once.
+// It returns false if the folder was already visited.
+func (w *walker) markVisited(folder string) bool {
+	if !w.options.FollowSymlinks {
+		return true
+	}
+
+	w.visitedMu.Lock()
+	defer w.visitedMu.Unlock()
+
+	realPath, err := filepath.EvalSymlinks(folder)
+	if err != nil {
+		return false
+	}
+
+	if _, ok := w.visitedPaths[realPath]; ok {
+		return false
+	}
+
+	w.visitedPaths[realPath] = struct{}{}
+
+	return true
+}
+
+func (w *walker) isMarker(name string) bool {
+	for _, marker := range w.options.Markers {
+		if marker == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,126 @@
+package fsscanner
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+
+	for _, dir := range []string{
+		"org/api/.git",
+		"org/api/internal",
+		"org/web/src",
+		"org/empty",
+		"solo/go.mod",
+		".hidden/repo",
+		"node_modules/pkg",
+		"deep/a/b/c",
+	} {
+		err := os.MkdirAll(filepath.Join(root, dir), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := os.Symlink(filepath.Join(root, "org"), filepath.Join(root, "org", "loop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		roots    []Root
+		options  Options
+		expected []string
+		failed   []string
+	}{
+		{
+			name:     "Direct children",
+			roots:    []Root{{Path: root, MaxDepth: 1}},
+			options:  Options{Markers: DefaultMarkers},
+			expected: []string{"deep", "node_modules", "org", "solo"},
+		},
+		{
+			name:     "Nested projects stop at markers",
+			roots:    []Root{{Path: root, MaxDepth: 3}},
+			options:  Options{Markers: DefaultMarkers, IgnorePatterns: []string{"node_modules"}},
+			expected: []string{"deep/a/b", "org/api", "org/empty", "org/web/src", "solo"},
+		},
+		{
+			name:     "Symlink loops are visited once",
+			roots:    []Root{{Path: root, MaxDepth: 3}},
+			options:  Options{Markers: DefaultMarkers, IgnorePatterns: []string{"/deep", "/node_modules"}, FollowSymlinks: true},
+			expected: []string{"org/api", "org/empty", "org/web/src", "solo"},
+		},
+		{
+			name:     "Unreadable roots don't stop the scan",
+			roots:    []Root{{Path: filepath.Join(root, "missing"), MaxDepth: 1}, {Path: filepath.Join(root, "org"), MaxDepth: 1}},
+			options:  Options{Markers: DefaultMarkers, Workers: 2},
+			expected: []string{"org/api", "org/empty", "org/web"},
+			failed:   []string{"missing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := make(chan scanResult)
+			go scan(newRootTasks(tt.roots, tt.options), tt.options.Workers, results)
+
+			relPaths := make([]string, 0)
+			failedPaths := make([]string, 0)
+
+			for result := range results {
+				relPath, _ := filepath.Rel(root, result.folder)
+
+				switch {
+				case result.err != nil:
+					failedPaths = append(failedPaths, filepath.ToSlash(relPath))
+				case result.isProject:
+					relPaths = append(relPaths, filepath.ToSlash(relPath))
+				}
+			}
+
+			slices.Sort(relPaths)
+
+			if !slices.Equal(relPaths, tt.expected) {
+				t.Errorf("Expected folders %q, got %q", tt.expected, relPaths)
+			}
+
+			if !slices.Equal(failedPaths, tt.failed) {
+				t.Errorf("Expected failed folders %q, got %q", tt.failed, failedPaths)
+			}
+		})
+	}
+}
+
+func TestParseRoot(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected Root
+	}{
+		{spec: "/code", expected: Root{Path: "/code", MaxDepth: DefaultMaxDepth}},
+		{spec: "/code/:3", expected: Root{Path: "/code", MaxDepth: 3}},
+		{spec: "/srv/host:web", expected: Root{Path: "/srv/host:web", MaxDepth: DefaultMaxDepth}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			root, err := ParseRoot(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if root != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, root)
+			}
+		})
+	}
+
+	if _, err := ParseRoot("/code:0"); err == nil {
+		t.Error("Expected an error for zero depth")
+	}
+}
@@ -0,0 +1,164 @@
+package fsscanner
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/verte-zerg/gession/internal/event"
+)
+
+const (
+	watchQueueSize = 100
+	// rescanDelay groups a burst of changes, like a clone, into a single rescan.
+	rescanDelay = 200 * time.Millisecond
+)
+
+// folderWatcher reports folders whose entries were created, deleted or moved.
+type folderWatcher interface {
+	add(folder string) error
+	remove(folder string)
+	changes() <-chan string
+}
+
+func (t *FSScanner) watcherChanges() <-chan string {
+	if t.watcher == nil {
+		return nil
+	}
+
+	return t.watcher.changes()
+}
+
+func (t *FSScanner) watch(folder string) {
+	if t.watcher == nil {
+		return
+	}
+
+	if err := t.watcher.add(folder); err != nil {
+		logger.Warn("could not watch folder", slog.String("folder", folder), slog.Any("error", err))
+	}
+}
+
+func (t *FSScanner) unwatch(folder string) {
+	if t.watcher != nil {
+		t.watcher.remove(folder)
+	}
+}
+
+// rescan scans the changed containers again with the depth rules of their roots.
+// Projects that appeared under the folders are sent as found, the ones that disappeared are sent as removed.
+func (t *FSScanner) rescan(changedFolders map[string]struct{}) {
+	walkers := make(map[Root]*walker)
+	tasks := make([]scanTask, 0, len(changedFolders))
+	scannedFolders := make([]string, 0, len(changedFolders))
+
+	for folder := range changedFolders {
+		container, ok := t.containers[folder]
+		if !ok || hasChangedAncestor(folder, changedFolders) {
+			continue
+		}
+
+		if _, ok := walkers[container.root]; !ok {
+			walkers[container.root] = newWalker(container.root, t.options)
+		}
+
+		tasks = append(tasks, scanTask{walker: walkers[container.root], folder: folder, depth: container.depth})
+		scannedFolders = append(scannedFolders, folder)
+	}
+
+	if len(tasks) == 0 {
+		return
+	}
+
+	logger.Info("rescanning folders", slog.Any("folders", scannedFolders))
+
+	results := make(chan scanResult, event.MaxQueue)
+	go scan(tasks, t.options.Workers, results)
+
+	projects := make(map[string]struct{})
+	containers := make(map[string]struct{})
+	failures := make([]event.FolderError, 0)
+
+	for result := range results {
+		switch {
+		case errors.Is(result.err, fs.ErrNotExist):
+			// The folder was removed after the change, its parent is rescanned as well
+		case result.err != nil:
+			failures = append(failures, event.FolderError{Path: result.folder, Message: result.err.Error()})
+		case result.isProject:
+			projects[result.folder] = struct{}{}
+		default:
+			containers[result.folder] = struct{}{}
+			t.addContainer(result)
+		}
+	}
+
+	for containerPath := range t.containers {
+		if _, ok := containers[containerPath]; !ok && isUnderAny(containerPath, scannedFolders) {
+			t.unwatch(containerPath)
+			delete(t.containers, containerPath)
+		}
+	}
+
+	removedIDs := make([]string, 0)
+
+	for folderPath := range t.folderNames {
+		if _, ok := projects[folderPath]; !ok && isUnderAny(folderPath, scannedFolders) {
+			removedIDs = append(removedIDs, folderID(folderPath))
+			delete(t.folderNames, folderPath)
+		}
+	}
+
+	if len(removedIDs) > 0 {
+		logger.Info("removed folders", slog.Int("count", len(removedIDs)))
+
+		t.outputEventCh <- event.Event{
+			Type: event.TypeRemovedFolders,
+			Data: event.RemovedFolders{
+				IDs: removedIDs,
+			},
+		}
+	}
+
+	addedFolders := make([]string, 0)
+
+	for folderPath := range projects {
+		if _, ok := t.folderNames[folderPath]; !ok {
+			addedFolders = append(addedFolders, folderPath)
+		}
+	}
+
+	if len(addedFolders) > 0 || len(removedIDs) > 0 {
+		t.flush(addedFolders, true)
+	}
+
+	t.reportFailures(failures)
+}
+
+func hasChangedAncestor(folder string, changedFolders map[string]struct{}) bool {
+	for changedFolder := range changedFolders {
+		if changedFolder != folder && isUnder(folder, changedFolder) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isUnderAny(folderPath string, folders []string) bool {
+	for _, folder := range folders {
+		if isUnder(folderPath, folder) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isUnder reports whether the path is the folder or is inside of it.
+func isUnder(folderPath, folder string) bool {
+	return folderPath == folder || strings.HasPrefix(folderPath, strings.TrimSuffix(folder, string(filepath.Separator))+string(filepath.Separator))
+}
@@ -0,0 +1,68 @@
+//go:build linux
+
+package fsscanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/verte-zerg/gession/internal/event"
+)
+
+func TestWatch(t *testing.T) {
+	root := t.TempDir()
+
+	err := os.MkdirAll(filepath.Join(root, "org", "api"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := New(Options{Watch: true})
+	outputCh := make(chan event.Event, event.MaxQueue)
+	scanner.SetOutputCh(outputCh)
+	scanner.Start()
+
+	scanner.GetInputCh() <- event.Event{Type: event.TypeListFolders, Data: []Root{{Path: root, MaxDepth: 2}}}
+
+	waitEvent := func(eventType event.Type) event.Event {
+		t.Helper()
+
+		for {
+			select {
+			case e := <-outputCh:
+				if e.Type == eventType {
+					return e
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Expected %s event", eventType)
+			}
+		}
+	}
+
+	listed, _ := waitEvent(event.TypeListedFolders).Data.(event.ListedFolders)
+	if len(listed.Sessions) != 1 || listed.Sessions[0].Directory != filepath.Join(root, "org", "api") {
+		t.Fatalf("Expected only the api folder, got %+v", listed.Sessions)
+	}
+
+	err = os.Mkdir(filepath.Join(root, "org", "web"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listed, _ = waitEvent(event.TypeListedFolders).Data.(event.ListedFolders)
+	if len(listed.Sessions) != 1 || listed.Sessions[0].Directory != filepath.Join(root, "org", "web") {
+		t.Fatalf("Expected the added web folder, got %+v", listed.Sessions)
+	}
+
+	err = os.Remove(filepath.Join(root, "org", "api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, _ := waitEvent(event.TypeRemovedFolders).Data.(event.RemovedFolders)
+	if len(removed.IDs) != 1 || removed.IDs[0] != folderID(filepath.Join(root, "org", "api")) {
+		t.Fatalf("Expected the api folder to be removed, got %+v", removed.IDs)
+	}
+}
@@ -0,0 +1,125 @@
+//go:build linux
+
+package fsscanner
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	watchMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_ONLYDIR
+	// readBufferSize fits at least one event with the longest name.
+	readBufferSize = 64 * (unix.SizeofInotifyEvent + unix.NAME_MAX + 1)
+)
+
+// inotifyWatcher reports folders whose entries were created, deleted or moved.
+type inotifyWatcher struct {
+	fd int
+
+	mu            sync.Mutex
+	descToFolder  map[int]string
+	folderToDesc  map[string]int
+	changedFolder chan string
+}
+
+func newWatcher() (folderWatcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("could not init inotify: %w", err)
+	}
+
+	watcher := &inotifyWatcher{
+		fd:            fd,
+		descToFolder:  make(map[int]string),
+		folderToDesc:  make(map[string]int),
+		changedFolder: make(chan string, watchQueueSize),
+	}
+
+	go watcher.reader()
+
+	return watcher, nil
+}
+
+func (w *inotifyWatcher) add(folder string) error {
+	desc, err := unix.InotifyAddWatch(w.fd, folder, watchMask)
+	if err != nil {
+		return fmt.Errorf("could not watch %s: %w", folder, err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.descToFolder[desc] = folder
+	w.folderToDesc[folder] = desc
+
+	return nil
+}
+
+func (w *inotifyWatcher) remove(folder string) {
+	w.mu.Lock()
+	desc, ok := w.folderToDesc[folder]
+	w.mu.Unlock()
+
+	if ok {
+		// The watch is forgotten when IN_IGNORED is read
+		_, _ = unix.InotifyRmWatch(w.fd, uint32(desc)) //nolint:gosec
+	}
+}
+
+func (w *inotifyWatcher) changes() <-chan string {
+	return w.changedFolder
+}
+
+func (w *inotifyWatcher) reader() {
+	buffer := make([]byte, readBufferSize)
+
+	for {
+		n, err := unix.Read(w.fd, buffer)
+		if err != nil {
+			if err == unix.EINTR {
+				continue
+			}
+
+			logger.Error("could not read inotify events, watching is stopped", slog.Any("error", err))
+
+			return
+		}
+
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			inotifyEvent := (*unix.InotifyEvent)(unsafe.Pointer(&buffer[offset])) //nolint:gosec
+			offset += unix.SizeofInotifyEvent + int(inotifyEvent.Len)
+
+			w.handleEvent(int(inotifyEvent.Wd), inotifyEvent.Mask)
+		}
+	}
+}
+
+func (w *inotifyWatcher) handleEvent(desc int, mask uint32) {
+	w.mu.Lock()
+	folder, ok := w.descToFolder[desc]
+
+	if mask&unix.IN_IGNORED != 0 {
+		delete(w.descToFolder, desc)
+
+		if w.folderToDesc[folder] == desc {
+			delete(w.folderToDesc, folder)
+		}
+	}
+	w.mu.Unlock()
+
+	if mask&unix.IN_Q_OVERFLOW != 0 {
+		logger.Warn("inotify queue overflowed, some changes are missed")
+	}
+
+	if !ok || mask&(unix.IN_CREATE|unix.IN_DELETE|unix.IN_MOVED_FROM|unix.IN_MOVED_TO) == 0 {
+		return
+	}
+
+	w.changedFolder <- filepath.Clean(folder)
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package fsscanner
+
+import (
+	"errors"
+)
+
+func newWatcher() (folderWatcher, error) {
+	return nil, errors.New("watching folders is supported only on linux")
+}
@@ -0,0 +1,277 @@
+package gitstatus
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/verte-zerg/gession/internal/event"
+	"github.com/verte-zerg/gession/internal/session"
+	"github.com/verte-zerg/gession/pkg/assert"
+	"github.com/verte-zerg/gession/pkg/logging"
+)
+
+const (
+	// workerCount is the number of git processes run concurrently.
+	workerCount = 4
+	// flushInterval is how often checked directories are sent.
+	flushInterval = 100 * time.Millisecond
+	// recheckInterval is how long a checked status is considered fresh, requests for it are skipped.
+	recheckInterval = 2 * time.Second
+
+	commitHashLen = 7
+)
+
+var (
+	logger = logging.GetInstance().WithGroup("gitstatus")
+)
+
+// Checker runs `git status` in directories and sends their statuses in batches.
+type Checker struct {
+	mu          sync.Mutex
+	inflight    map[string]struct{}
+	checkedTime map[string]time.Time
+
+	directoriesCh chan string
+	resultsCh     chan checkResult
+
+	inputEventCh  chan event.Event
+	outputEventCh chan event.Event
+}
+
+type checkResult struct {
+	directory string
+	status    *session.GitStatus
+	err       error
+}
+
+func New() *Checker {
+	return &Checker{
+		inflight:      make(map[string]struct{}),
+		checkedTime:   make(map[string]time.Time),
+		directoriesCh: make(chan string, event.MaxQueue),
+		resultsCh:     make(chan checkResult, event.MaxQueue),
+		inputEventCh:  make(chan event.Event, event.MaxQueue),
+		outputEventCh: make(chan event.Event, event.MaxQueue),
+	}
+}
+
+func (c *Checker) Start() {
+	logger.Info("starting git status checker")
+
+	go c.handler()
+	go c.sender()
+
+	for range workerCount {
+		go c.worker()
+	}
+}
+
+func (c *Checker) GetInputCh() chan event.Event {
+	return c.inputEventCh
+}
+
+func (c *Checker) SetOutputCh(outputCh chan event.Event) {
+	c.outputEventCh = outputCh
+}
+
+func (c *Checker) handler() {
+	for {
+		e := <-c.inputEventCh
+
+		assert.Assert(e.Type == event.TypeCheckGitStatus, "git status checker supports only check git status event")
+
+		request, ok := e.Data.(event.CheckGitStatus)
+		assert.Assert(ok, "data should be a CheckGitStatus")
+
+		for _, directory := range request.Directories {
+			if c.startCheck(directory) {
+				c.directoriesCh <- directory
+			}
+		}
+	}
+}
+
+// startCheck marks the directory as being checked, it returns false if it's being checked or was checked recently.
+func (c *Checker) startCheck(directory string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.inflight[directory]; ok || directory == "" {
+		return false
+	}
+
+	if checkedTime, ok := c.checkedTime[directory]; ok && time.Since(checkedTime) < recheckInterval {
+		return false
+	}
+
+	c.inflight[directory] = struct{}{}
+
+	return true
+}
+
+func (c *Checker) finishCheck(directory string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.inflight, directory)
+	c.checkedTime[directory] = time.Now()
+}
+
+func (c *Checker) worker() {
+	for {
+		directory := <-c.directoriesCh
+
+		status, err := checkDirectory(directory)
+		if err != nil {
+			logger.Warn("could not check git status", slog.String("directory", directory), slog.Any("error", err))
+		}
+
+		c.finishCheck(directory)
+		c.resultsCh <- checkResult{directory: directory, status: status, err: err}
+	}
+}
+
+// sender groups checked directories into batches, so a large list is rendered a few times instead of once per directory.
+func (c *Checker) sender() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	statuses := make(map[string]*session.GitStatus)
+	errs := make(map[string]string)
+
+	for {
+		select {
+		case result := <-c.resultsCh:
+			statuses[result.directory] = result.status
+
+			if result.err != nil {
+				errs[result.directory] = result.err.Error()
+			} else {
+				delete(errs, result.directory)
+			}
+		case <-ticker.C:
+			if len(statuses) == 0 {
+				continue
+			}
+
+			c.outputEventCh <- event.Event{
+				Type: event.TypeCheckedGitStatus,
+				Data: event.CheckedGitStatus{
+					Statuses: statuses,
+					Errors:   errs,
+				},
+			}
+
+			statuses = make(map[string]*session.GitStatus)
+			errs = make(map[string]string)
+		}
+	}
+}
+
+// checkDirectory returns the status of the repository in the directory, or nil if it's not a repository.
+func checkDirectory(directory string) (*session.GitStatus, error) {
+	// Optional locks are skipped, so checking doesn't interfere with git commands run by the user
+	cmd := exec.Command("git", "--no-optional-locks", "-C", directory, "status", "--porcelain=v2", "--branch")
+
+	stderr := bytes.Buffer{}
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && strings.Contains(stderr.String(), "not a git repository") {
+			return nil, nil //nolint:nilnil
+		}
+
+		return nil, fmt.Errorf("could not run git status: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	status, err := ParseStatus(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	status.Worktrees, err = listWorktrees(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// ParseStatus parses the output of `git status --porcelain=v2 --branch`.
+func ParseStatus(output string) (*session.GitStatus, error) {
+	status := &session.GitStatus{}
+	hasHead := false
+
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+
+		header, isHeader := strings.CutPrefix(line, "# ")
+		if !isHeader {
+			// Every entry is a changed, unmerged or untracked file
+			status.IsDirty = true
+
+			continue
+		}
+
+		key, value, _ := strings.Cut(header, " ")
+
+		switch key {
+		case "branch.oid":
+			if value != "(initial)" {
+				status.Commit = value[:min(len(value), commitHashLen)]
+			}
+		case "branch.head":
+			hasHead = true
+
+			if value == "(detached)" {
+				status.IsDetached = true
+			} else {
+				status.Branch = value
+			}
+		case "branch.ab":
+			var err error
+
+			status.Ahead, status.Behind, err = parseAheadBehind(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !hasHead {
+		return nil, errors.New("no branch header in git status")
+	}
+
+	return status, nil
+}
+
+// parseAheadBehind parses `+<ahead> -<behind>`.
+func parseAheadBehind(value string) (int, int, error) {
+	aheadValue, behindValue, ok := strings.Cut(value, " ")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid ahead/behind header: %q", value)
+	}
+
+	ahead, err := strconv.Atoi(strings.TrimPrefix(aheadValue, "+"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid ahead count: %w", err)
+	}
+
+	behind, err := strconv.Atoi(strings.TrimPrefix(behindValue, "-"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid behind count: %w", err)
+	}
+
+	return ahead, behind, nil
+}
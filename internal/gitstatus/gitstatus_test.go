@@ -0,0 +1,106 @@
+package gitstatus_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/verte-zerg/gession/internal/gitstatus"
+	"github.com/verte-zerg/gession/internal/session"
+)
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected session.GitStatus
+	}{
+		{
+			name: "Clean branch with upstream",
+			output: "# branch.oid 4290d7e1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7\n" +
+				"# branch.head main\n" +
+				"# branch.upstream origin/main\n" +
+				"# branch.ab +2 -1\n",
+			expected: session.GitStatus{Branch: "main", Commit: "4290d7e", Ahead: 2, Behind: 1},
+		},
+		{
+			name: "Dirty branch without upstream",
+			output: "# branch.oid 4290d7e1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7\n" +
+				"# branch.head feature/login\n" +
+				"1 .M N... 100644 100644 100644 3f2a 3f2a README.md\n" +
+				"? notes.txt\n",
+			expected: session.GitStatus{Branch: "feature/login", Commit: "4290d7e", IsDirty: true},
+		},
+		{
+			name: "Detached head",
+			output: "# branch.oid 4290d7e1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7\n" +
+				"# branch.head (detached)\n",
+			expected: session.GitStatus{Commit: "4290d7e", IsDetached: true},
+		},
+		{
+			name: "Repository without commits",
+			output: "# branch.oid (initial)\n" +
+				"# branch.head main\n",
+			expected: session.GitStatus{Branch: "main"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := gitstatus.ParseStatus(tt.output)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(*status, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, *status)
+			}
+		})
+	}
+}
+
+func TestParseStatusErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{name: "No branch header", output: "? notes.txt\n"},
+		{name: "Invalid ahead count", output: "# branch.head main\n# branch.ab +x -1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := gitstatus.ParseStatus(tt.output); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestParseWorktrees(t *testing.T) {
+	output := "worktree /code/api\n" +
+		"HEAD 4290d7e1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7\n" +
+		"branch refs/heads/main\n" +
+		"\n" +
+		"worktree /code/.api-worktrees/feature/login\n" +
+		"HEAD b1b51ec1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7\n" +
+		"branch refs/heads/feature/login\n" +
+		"locked\n" +
+		"\n" +
+		"worktree /code/.api-worktrees/review\n" +
+		"HEAD 9604ae71b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7\n" +
+		"detached\n" +
+		"\n" +
+		"worktree /code/api.git\n" +
+		"bare\n"
+
+	expected := []session.GitWorktree{
+		{Path: "/code/api", Branch: "main", Commit: "4290d7e"},
+		{Path: "/code/.api-worktrees/feature/login", Branch: "feature/login", Commit: "b1b51ec"},
+		{Path: "/code/.api-worktrees/review", Commit: "9604ae7", IsDetached: true},
+	}
+
+	worktrees := gitstatus.ParseWorktrees(output)
+	if !reflect.DeepEqual(worktrees, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, worktrees)
+	}
+}
@@ -0,0 +1,109 @@
+package gitstatus
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/verte-zerg/gession/internal/session"
+)
+
+// listWorktrees returns linked worktrees of the repository in the directory.
+// Git is run only if the repository has linked worktrees, which is the rare case.
+func listWorktrees(directory string) ([]session.GitWorktree, error) {
+	if _, err := os.Stat(filepath.Join(directory, ".git", "worktrees")); err != nil {
+		return nil, nil
+	}
+
+	output, err := runGit(directory, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+
+	worktrees := ParseWorktrees(output)
+	if len(worktrees) == 0 {
+		return nil, nil
+	}
+
+	// The first worktree is the main one, it's the directory itself
+	return worktrees[1:], nil
+}
+
+// ParseWorktrees parses the output of `git worktree list --porcelain`, bare worktrees are skipped.
+func ParseWorktrees(output string) []session.GitWorktree {
+	worktrees := make([]session.GitWorktree, 0)
+
+	for _, block := range strings.Split(strings.TrimSpace(output), "\n\n") {
+		worktree := session.GitWorktree{}
+		isBare := false
+
+		for _, line := range strings.Split(block, "\n") {
+			key, value, _ := strings.Cut(line, " ")
+
+			switch key {
+			case "worktree":
+				worktree.Path = value
+			case "HEAD":
+				worktree.Commit = value[:min(len(value), commitHashLen)]
+			case "branch":
+				worktree.Branch = strings.TrimPrefix(value, "refs/heads/")
+			case "detached":
+				worktree.IsDetached = true
+			case "bare":
+				isBare = true
+			}
+		}
+
+		if worktree.Path != "" && !isBare {
+			worktrees = append(worktrees, worktree)
+		}
+	}
+
+	return worktrees
+}
+
+// WorktreePath returns where a worktree for the branch is created: a hidden folder next to the repository,
+// so worktrees of `~/code/api` are in `~/code/.api-worktrees` and aren't listed as prime folders themselves.
+func WorktreePath(repository, branch string) string {
+	return filepath.Join(filepath.Dir(repository), "."+filepath.Base(repository)+"-worktrees", branch)
+}
+
+// AddWorktree creates a worktree for the branch and returns its path.
+// An existing local or remote branch is checked out, otherwise a new branch is created from HEAD.
+func AddWorktree(repository, branch string) (string, error) {
+	worktreePath := WorktreePath(repository, branch)
+
+	refs, err := runGit(repository, "for-each-ref", "--format=%(refname)", "refs/heads/"+branch, "refs/remotes/*/"+branch)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"worktree", "add", worktreePath, branch}
+	if strings.TrimSpace(refs) == "" {
+		args = []string{"worktree", "add", "-b", branch, worktreePath}
+	}
+
+	_, err = runGit(repository, args...)
+	if err != nil {
+		return "", err
+	}
+
+	return worktreePath, nil
+}
+
+func runGit(directory string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", directory}, args...)...)
+
+	stderr := bytes.Buffer{}
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("could not run git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return string(output), nil
+}
@@ -0,0 +1,230 @@
+package hibernate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/verte-zerg/gession/internal/session"
+	"github.com/verte-zerg/gession/internal/tmux"
+	"github.com/verte-zerg/gession/internal/workspace"
+	"github.com/verte-zerg/gession/pkg/logging"
+)
+
+const (
+	// Version is the format version of the record, records of other versions aren't listed.
+	Version = 1
+	// IDPrefix marks listed hibernated sessions, they don't have tmux IDs.
+	IDPrefix = "hibernated_"
+
+	recordFileName = "session.json"
+	dirMode        = 0o755
+	fileMode       = 0o600
+)
+
+var (
+	logger = logging.GetInstance().WithGroup("hibernate")
+)
+
+// Record is a hibernated session, the template of the saved session has scrollback files of its panes.
+type Record struct {
+	Version      int               `json:"version"`
+	HibernatedAt time.Time         `json:"hibernatedAt"`
+	Saved        workspace.Session `json:"session"`
+}
+
+// Session returns the listed session waking the record.
+func (r Record) Session() *session.Session {
+	template := r.Saved.Template
+
+	return &session.Session{
+		ID:           IDPrefix + r.Saved.Name,
+		Name:         r.Saved.Name,
+		Directory:    r.Saved.Directory,
+		Template:     &template,
+		IsHibernated: true,
+	}
+}
+
+// Save stores the layout, working directories and scrollback of every pane of the live session.
+// A previous record of a session with the same name is replaced.
+func Save(dir string, liveSession *session.Session) error {
+	commands, err := workspace.ChildCommands()
+	if err != nil {
+		logger.Warn("could not list pane commands, only shells are saved", slog.Any("error", err))
+	}
+
+	record := Record{
+		Version:      Version,
+		HibernatedAt: time.Now(),
+		Saved:        workspace.FromSessions([]*session.Session{liveSession}, commands).Sessions[0],
+	}
+
+	// Saved commands are run again on wake, so only the safe ones are kept
+	record.Saved.Template.FilterCommands(workspace.AllowPrograms(workspace.DefaultRestoreCommands))
+
+	sessionDir := sessionPath(dir, liveSession.Name)
+
+	err = os.RemoveAll(sessionDir)
+	if err != nil {
+		return fmt.Errorf("could not remove previous record: %w", err)
+	}
+
+	err = os.MkdirAll(sessionDir, dirMode)
+	if err != nil {
+		return fmt.Errorf("could not create record directory: %w", err)
+	}
+
+	for i, window := range liveSession.Windows {
+		for j, pane := range window.Panes {
+			content, err := tmux.CapturePaneHistory(pane.ID)
+			if err != nil {
+				return err
+			}
+
+			scrollbackPath := filepath.Join(sessionDir, strconv.Itoa(i)+"-"+strconv.Itoa(j)+".txt")
+
+			// The last lines are usually empty lines of the screen below the prompt
+			err = os.WriteFile(scrollbackPath, []byte(strings.TrimRight(content, "\n")+"\n"), fileMode)
+			if err != nil {
+				return fmt.Errorf("could not write scrollback: %w", err)
+			}
+
+			record.Saved.Template.Windows[i].Panes[j].Scrollback = scrollbackPath
+		}
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode record: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(sessionDir, recordFileName), data, fileMode)
+	if err != nil {
+		return fmt.Errorf("could not write record: %w", err)
+	}
+
+	logger.Info("hibernated session", slog.String("name", liveSession.Name), slog.String("directory", sessionDir))
+
+	return nil
+}
+
+// List returns the hibernated sessions sorted by name, records that can't be read are skipped.
+func List(dir string) ([]Record, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read hibernated sessions: %w", err)
+	}
+
+	records := make([]Record, 0, len(dirEntries))
+
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() {
+			continue
+		}
+
+		sessionDir := filepath.Join(dir, dirEntry.Name())
+
+		record, err := readRecord(sessionDir)
+		if errors.Is(err, fs.ErrNotExist) {
+			// The session was woken, its directory is removed once the panes printed their scrollback
+			_ = os.Remove(sessionDir)
+
+			continue
+		}
+
+		if err != nil {
+			logger.Warn("skip hibernated session", slog.String("directory", dirEntry.Name()), slog.Any("error", err))
+
+			continue
+		}
+
+		records = append(records, record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Saved.Name < records[j].Saved.Name
+	})
+
+	return records, nil
+}
+
+// Wake recreates the hibernated session, every pane prints its scrollback before the shell starts.
+func Wake(dir, name string) error {
+	sessionDir := sessionPath(dir, name)
+
+	record, err := readRecord(sessionDir)
+	if err != nil {
+		return err
+	}
+
+	// The record is kept, so the session can be woken after the live one is renamed or killed
+	if tmux.HasSession(record.Saved.Name) {
+		return fmt.Errorf("could not wake session: a live session named %q exists", record.Saved.Name)
+	}
+
+	template := record.Saved.Template
+
+	err = tmux.CreateTemplatedSession(record.Saved.Name, record.Saved.Directory, &template)
+	if err != nil {
+		return err
+	}
+
+	// Scrollback files are removed by the panes once they are printed
+	err = os.Remove(filepath.Join(sessionDir, recordFileName))
+	if err != nil {
+		return fmt.Errorf("could not remove record: %w", err)
+	}
+
+	logger.Info("woke session", slog.String("name", name))
+
+	return nil
+}
+
+// Remove drops the hibernated session with its scrollback.
+func Remove(dir, name string) error {
+	err := os.RemoveAll(sessionPath(dir, name))
+	if err != nil {
+		return fmt.Errorf("could not remove hibernated session: %w", err)
+	}
+
+	return nil
+}
+
+func readRecord(sessionDir string) (Record, error) {
+	data, err := os.ReadFile(filepath.Join(sessionDir, recordFileName))
+	if err != nil {
+		return Record{}, fmt.Errorf("could not read record: %w", err)
+	}
+
+	record := Record{}
+
+	err = json.Unmarshal(data, &record)
+	if err != nil {
+		return Record{}, fmt.Errorf("could not parse record: %w", err)
+	}
+
+	if record.Version != Version {
+		return Record{}, fmt.Errorf("unsupported record version %d, expected %d", record.Version, Version)
+	}
+
+	return record, nil
+}
+
+// sessionPath returns the directory of the session record, session names may contain path separators.
+func sessionPath(dir, name string) string {
+	return filepath.Join(dir, url.PathEscape(name))
+}
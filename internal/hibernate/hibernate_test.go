@@ -0,0 +1,64 @@
+package hibernate_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/verte-zerg/gession/internal/hibernate"
+)
+
+func TestListAndRemove(t *testing.T) {
+	dir := t.TempDir()
+
+	record := `{"version": 1, "session": {"name": "work/api", "directory": "/code/api", "template": {"windows": [{"panes": [{"dir": "/code/api"}]}]}}}`
+
+	for sessionDir, data := range map[string]string{
+		"work%2Fapi": record,
+		"old":        `{"version": 0, "session": {"name": "old"}}`,
+	} {
+		if err := os.MkdirAll(filepath.Join(dir, sessionDir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, sessionDir, "session.json"), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A woken session whose panes printed the scrollback
+	if err := os.MkdirAll(filepath.Join(dir, "woken"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := hibernate.List(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+
+	listed := records[0].Session()
+	if listed.ID != hibernate.IDPrefix+"work/api" || listed.Name != "work/api" || !listed.IsHibernated {
+		t.Errorf("Unexpected listed session: %+v", listed)
+	}
+
+	if !reflect.DeepEqual(*listed.Template, records[0].Saved.Template) {
+		t.Errorf("Expected template %+v, got %+v", records[0].Saved.Template, *listed.Template)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "woken")); !os.IsNotExist(err) {
+		t.Error("Expected the directory of the woken session to be removed")
+	}
+
+	if err := hibernate.Remove(dir, "work/api"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "work%2Fapi")); !os.IsNotExist(err) {
+		t.Error("Expected the hibernated session to be removed")
+	}
+}
@@ -0,0 +1,265 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/verte-zerg/gession/pkg/logging"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// MaxEntries is how many of the latest entries are kept, older ones are dropped when the history is loaded.
+	MaxEntries = 1000
+	// MaxAge is how long an entry counts, older entries are dropped when the history is loaded.
+	MaxAge = 90 * 24 * time.Hour
+
+	dirMode  = 0o755
+	fileMode = 0o600
+)
+
+var (
+	logger = logging.GetInstance().WithGroup("history")
+
+	// weights of a visit by its age, recent visits count more, like in zoxide and the Firefox address bar.
+	weights = []struct {
+		age    time.Duration
+		weight int
+	}{
+		{age: time.Hour, weight: 16},
+		{age: 24 * time.Hour, weight: 8},
+		{age: 7 * 24 * time.Hour, weight: 4},
+		{age: 30 * 24 * time.Hour, weight: 2},
+		{age: MaxAge, weight: 1},
+	}
+)
+
+// Action is what gession did with the session.
+type Action string
+
+const (
+	ActionSwitch Action = "switch"
+	ActionCreate Action = "create"
+	// ActionClose is a killed session reported by the tmux hook, it's kept in the log but isn't a visit.
+	ActionClose Action = "close"
+)
+
+// Entry is a visit of a session, the directory is the folder it's started in.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Action    Action    `json:"action"`
+	Name      string    `json:"name"`
+	Directory string    `json:"directory,omitempty"`
+}
+
+// History is the list of visits of sessions, stored as JSON lines, so a visit is recorded by appending a line.
+type History struct {
+	path    string
+	entries []Entry
+}
+
+// Load reads the history from the file, a missing file is an empty history.
+// Lines that can't be decoded are skipped, so a line cut by a crash doesn't lose the history.
+func Load(historyPath string) (*History, error) {
+	history := &History{path: historyPath, entries: make([]Entry, 0)}
+
+	lines, err := history.read()
+	if errors.Is(err, fs.ErrNotExist) {
+		return history, nil
+	}
+
+	if err != nil || len(history.entries) == lines {
+		return history, err
+	}
+
+	// The file is compacted when it has dropped entries, so it doesn't grow forever.
+	// It's read again under the lock, so entries recorded by other gession processes meanwhile aren't lost.
+	err = history.withLock(func() error {
+		lines, err := history.read()
+		if err != nil || len(history.entries) == lines {
+			return err
+		}
+
+		return history.write()
+	})
+
+	return history, err
+}
+
+// read replaces the entries with the ones in the file and prunes them, it returns the number of lines in the file.
+func (h *History) read() (int, error) {
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		return 0, fmt.Errorf("could not read history: %w", err)
+	}
+
+	h.entries = make([]Entry, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lines := 0
+
+	for scanner.Scan() {
+		lines++
+
+		entry := Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			logger.Warn("skip invalid history line", slog.Int("line", lines), slog.Any("error", err))
+
+			continue
+		}
+
+		h.entries = append(h.entries, entry)
+	}
+
+	h.prune(time.Now())
+
+	return lines, nil
+}
+
+// Record adds the entry to the history and appends it to the file.
+func (h *History) Record(entry Entry) error {
+	h.entries = append(h.entries, entry)
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("could not encode history entry: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(h.path), dirMode)
+	if err != nil {
+		return fmt.Errorf("could not create history directory: %w", err)
+	}
+
+	return h.withLock(func() error {
+		file, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, fileMode)
+		if err != nil {
+			return fmt.Errorf("could not open history: %w", err)
+		}
+
+		_, err = file.Write(append(data, '\n'))
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+
+		if err != nil {
+			return fmt.Errorf("could not write history: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// withLock runs the function holding the lock of the history, the tmux hooks record visits from other gession processes.
+// The lock is a separate file, because the history file is replaced when it's compacted.
+func (h *History) withLock(locked func() error) error {
+	lockFile, err := os.OpenFile(h.path+".lock", os.O_CREATE|os.O_RDWR, fileMode)
+	if err != nil {
+		return fmt.Errorf("could not open history lock: %w", err)
+	}
+	// Closing the file releases the lock
+	defer lockFile.Close()
+
+	err = unix.Flock(int(lockFile.Fd()), unix.LOCK_EX) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("could not lock history: %w", err)
+	}
+
+	return locked()
+}
+
+// SessionScores returns the frecency of every visited session name: every visit adds a weight, the more recent the visit, the higher.
+func (h *History) SessionScores(now time.Time) map[string]int {
+	return h.scores(now, func(entry Entry) string { return entry.Name })
+}
+
+// FolderScores returns the frecency of every visited directory, visits of all sessions started in it count.
+func (h *History) FolderScores(now time.Time) map[string]int {
+	return h.scores(now, func(entry Entry) string { return entry.Directory })
+}
+
+func (h *History) scores(now time.Time, keyOf func(Entry) string) map[string]int {
+	scores := make(map[string]int)
+
+	for _, entry := range h.entries {
+		if key := keyOf(entry); key != "" && entry.Action != ActionClose {
+			scores[key] += weight(now.Sub(entry.Time))
+		}
+	}
+
+	return scores
+}
+
+// IsRecentlyVisited reports whether the session was switched to or created within the interval before now.
+func (h *History) IsRecentlyVisited(name string, now time.Time, interval time.Duration) bool {
+	for i := len(h.entries) - 1; i >= 0 && now.Sub(h.entries[i].Time) < interval; i-- {
+		if h.entries[i].Name == name && h.entries[i].Action != ActionClose {
+			return true
+		}
+	}
+
+	return false
+}
+
+func weight(age time.Duration) int {
+	for _, w := range weights {
+		if age < w.age {
+			return w.weight
+		}
+	}
+
+	return 0
+}
+
+// prune drops entries older than MaxAge and keeps the latest MaxEntries.
+func (h *History) prune(now time.Time) {
+	entries := make([]Entry, 0, len(h.entries))
+
+	for _, entry := range h.entries {
+		if now.Sub(entry.Time) < MaxAge {
+			entries = append(entries, entry)
+		}
+	}
+
+	h.entries = entries[max(0, len(entries)-MaxEntries):]
+}
+
+// write replaces the file with the entries atomically.
+func (h *History) write() error {
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+
+	for _, entry := range h.entries {
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("could not encode history entry: %w", err)
+		}
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(h.path), filepath.Base(h.path)+".*")
+	if err != nil {
+		return fmt.Errorf("could not create history file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(buffer.Bytes())
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not write history file: %w", err)
+	}
+
+	err = os.Rename(tmpFile.Name(), h.path)
+	if err != nil {
+		return fmt.Errorf("could not replace history file: %w", err)
+	}
+
+	return nil
+}
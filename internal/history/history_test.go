@@ -0,0 +1,154 @@
+package history_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/verte-zerg/gession/internal/history"
+)
+
+func TestLoadAndScores(t *testing.T) {
+	historyPath := filepath.Join(t.TempDir(), "gession", "history.jsonl")
+	now := time.Now()
+
+	h, err := history.Load(historyPath)
+	if err != nil {
+		t.Fatalf("Unexpected error for a missing history: %v", err)
+	}
+
+	for _, entry := range []history.Entry{
+		{Time: now.Add(-time.Minute), Action: history.ActionCreate, Name: "api", Directory: "/code/api"},
+		{Time: now.Add(-2 * time.Hour), Action: history.ActionSwitch, Name: "api", Directory: "/code/api"},
+		{Time: now.Add(-3 * 24 * time.Hour), Action: history.ActionSwitch, Name: "web", Directory: "/code/web"},
+		{Time: now.Add(-20 * 24 * time.Hour), Action: history.ActionSwitch, Name: "notes"},
+		{Time: now.Add(-10 * 24 * time.Hour), Action: history.ActionClose, Name: "notes"},
+		{Time: now.Add(-60 * 24 * time.Hour), Action: history.ActionSwitch, Name: "web", Directory: "/code/web"},
+		{Time: now.Add(-100 * 24 * time.Hour), Action: history.ActionSwitch, Name: "old", Directory: "/code/old"},
+	} {
+		if err := h.Record(entry); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// A line cut by a crash is skipped
+	file, err := os.OpenFile(historyPath, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := file.WriteString(`{"time": "20`); err != nil {
+		t.Fatal(err)
+	}
+
+	file.Close()
+
+	h, err = history.Load(historyPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedSessions := map[string]int{"api": 24, "web": 5, "notes": 2}
+	if scores := h.SessionScores(now); !reflect.DeepEqual(scores, expectedSessions) {
+		t.Errorf("Expected session scores %v, got %v", expectedSessions, scores)
+	}
+
+	expectedFolders := map[string]int{"/code/api": 24, "/code/web": 5}
+	if scores := h.FolderScores(now); !reflect.DeepEqual(scores, expectedFolders) {
+		t.Errorf("Expected folder scores %v, got %v", expectedFolders, scores)
+	}
+
+	// The expired entry and the broken line are dropped from the file
+	data, err := os.ReadFile(historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := strings.Count(string(data), "\n"); lines != 6 || strings.Contains(string(data), "old") {
+		t.Errorf("Expected the history to be compacted to 6 entries, got %d lines:\n%s", lines, data)
+	}
+}
+
+func TestIsRecentlyVisited(t *testing.T) {
+	h, err := history.Load(filepath.Join(t.TempDir(), "history.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+
+	for _, entry := range []history.Entry{
+		{Time: now.Add(-time.Minute), Action: history.ActionSwitch, Name: "old"},
+		{Time: now.Add(-time.Second), Action: history.ActionCreate, Name: "api"},
+		{Time: now.Add(-time.Second), Action: history.ActionClose, Name: "web"},
+	} {
+		if err := h.Record(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{name: "api", expected: true},
+		{name: "web", expected: false},
+		{name: "old", expected: false},
+		{name: "new", expected: false},
+	}
+
+	for _, test := range tests {
+		if visited := h.IsRecentlyVisited(test.name, now, 2*time.Second); visited != test.expected {
+			t.Errorf("IsRecentlyVisited(%q) = %v, expected %v", test.name, visited, test.expected)
+		}
+	}
+}
+
+func TestCompactionKeepsConcurrentRecords(t *testing.T) {
+	historyPath := filepath.Join(t.TempDir(), "history.jsonl")
+	now := time.Now()
+	iterations, recordsPerLoad := 50, 10
+
+	recorder, err := history.Load(historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range iterations {
+		// The expired entry makes the next load compact the file while a visit is recorded
+		if err := recorder.Record(history.Entry{Time: now.Add(-2 * history.MaxAge), Action: history.ActionSwitch, Name: "old"}); err != nil {
+			t.Fatal(err)
+		}
+
+		done := make(chan error)
+
+		go func() {
+			_, err := history.Load(historyPath)
+			done <- err
+		}()
+
+		for j := range recordsPerLoad {
+			entry := history.Entry{Time: now, Action: history.ActionSwitch, Name: "api", Directory: strconv.Itoa(i*recordsPerLoad + j)}
+			if err := recorder.Record(entry); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h, err := history.Load(historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if scores := h.FolderScores(now); len(scores) != iterations*recordsPerLoad {
+		t.Errorf("Expected %d recorded folders, got %d", iterations*recordsPerLoad, len(scores))
+	}
+}
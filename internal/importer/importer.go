@@ -0,0 +1,186 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/verte-zerg/gession/internal/session"
+	"github.com/verte-zerg/gession/internal/sessiontemplate"
+	"github.com/verte-zerg/gession/internal/workspace"
+	"github.com/verte-zerg/gession/pkg/logging"
+)
+
+const (
+	SourceResurrect  = "resurrect"
+	SourceTmuxinator = "tmuxinator"
+)
+
+var (
+	logger = logging.GetInstance().WithGroup("importer")
+)
+
+// Entry is a session defined by another tool, converted to a template.
+type Entry struct {
+	Name      string
+	Directory string
+	Source    string
+	Template  sessiontemplate.Template
+}
+
+// Session returns a prime session creating the entry, its ID is unique per source and name.
+func (e Entry) Session() *session.Session {
+	template := e.Template
+
+	return &session.Session{
+		ID:        "notexisted_" + e.Source + ":" + e.Name,
+		Name:      session.SanitizeName(e.Name),
+		Directory: e.Directory,
+		Source:    e.Source,
+		Template:  &template,
+	}
+}
+
+// Paths are where definitions are looked up, empty paths are skipped.
+type Paths struct {
+	// ResurrectFile is a tmux-resurrect save file, usually the `last` link in the resurrect directory.
+	ResurrectFile string
+	// TmuxinatorDirs contain tmuxinator projects, one YAML file per project.
+	TmuxinatorDirs []string
+}
+
+// DefaultPaths returns the locations tmux-resurrect and tmuxinator use by default.
+func DefaultPaths(homeDir, dataHome, configHome string) Paths {
+	paths := Paths{}
+
+	for _, resurrectDir := range []string{
+		filepath.Join(dataHome, "tmux", "resurrect"),
+		filepath.Join(homeDir, ".tmux", "resurrect"),
+	} {
+		if _, err := os.Stat(filepath.Join(resurrectDir, "last")); err == nil {
+			paths.ResurrectFile = filepath.Join(resurrectDir, "last")
+
+			break
+		}
+	}
+
+	if tmuxinatorConfig := os.Getenv("TMUXINATOR_CONFIG"); tmuxinatorConfig != "" {
+		paths.TmuxinatorDirs = append(paths.TmuxinatorDirs, tmuxinatorConfig)
+	}
+
+	paths.TmuxinatorDirs = append(paths.TmuxinatorDirs,
+		filepath.Join(configHome, "tmuxinator"),
+		filepath.Join(homeDir, ".tmuxinator"),
+	)
+
+	return paths
+}
+
+// Load reads all definitions found in the paths.
+// Files that can't be parsed are skipped and returned as errors, so one broken project doesn't hide the rest.
+func Load(paths Paths) ([]Entry, []error) {
+	entries := make([]Entry, 0)
+	errs := make([]error, 0)
+
+	if paths.ResurrectFile != "" {
+		resurrectEntries, err := readResurrectFile(paths.ResurrectFile)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		entries = append(entries, resurrectEntries...)
+	}
+
+	for _, tmuxinatorDir := range paths.TmuxinatorDirs {
+		dirEntries, err := os.ReadDir(tmuxinatorDir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not read tmuxinator directory: %w", err))
+
+			continue
+		}
+
+		for _, dirEntry := range dirEntries {
+			ext := filepath.Ext(dirEntry.Name())
+			if dirEntry.IsDir() || (ext != ".yml" && ext != ".yaml") {
+				continue
+			}
+
+			entry, err := readTmuxinatorFile(filepath.Join(tmuxinatorDir, dirEntry.Name()))
+			if err != nil {
+				errs = append(errs, err)
+
+				continue
+			}
+
+			entries = append(entries, entry)
+		}
+	}
+
+	logger.Info("imported sessions", slog.Int("entries", len(entries)), slog.Int("errors", len(errs)))
+
+	return entries, errs
+}
+
+func readResurrectFile(resurrectPath string) ([]Entry, error) {
+	data, err := os.ReadFile(resurrectPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read tmux-resurrect file: %w", err)
+	}
+
+	entries, err := ParseResurrect(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", resurrectPath, err)
+	}
+
+	// Saved commands are run again like on restore, so only the safe ones are kept
+	isAllowed := workspace.AllowPrograms(workspace.DefaultRestoreCommands)
+	for i := range entries {
+		entries[i].Template.FilterCommands(isAllowed)
+	}
+
+	return entries, nil
+}
+
+func readTmuxinatorFile(projectPath string) (Entry, error) {
+	data, err := os.ReadFile(projectPath)
+	if err != nil {
+		return Entry{}, fmt.Errorf("could not read tmuxinator project: %w", err)
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return Entry{}, fmt.Errorf("could not determine home directory: %w", err)
+	}
+
+	entry, err := ParseTmuxinator(data, homeDir)
+	if err != nil {
+		return Entry{}, fmt.Errorf("%s: %w", projectPath, err)
+	}
+
+	if entry.Name == "" {
+		entry.Name = strings.TrimSuffix(filepath.Base(projectPath), filepath.Ext(projectPath))
+	}
+
+	return entry, nil
+}
+
+// expandHome replaces the leading `~` with the home directory.
+func expandHome(directory, homeDir string) string {
+	if directory == "~" {
+		return homeDir
+	}
+
+	if rest, ok := strings.CutPrefix(directory, "~/"); ok {
+		return filepath.Join(homeDir, rest)
+	}
+
+	return directory
+}
@@ -0,0 +1,130 @@
+package importer_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/verte-zerg/gession/internal/importer"
+	"github.com/verte-zerg/gession/internal/sessiontemplate"
+)
+
+func TestParseResurrect(t *testing.T) {
+	data := "pane\tapi\t1\t1\t:*\t0\tvm\t:/code/api\t0\tnvim\t:nvim main.go\n" +
+		"pane\tapi\t1\t1\t:*\t1\tvm\t:/code/my\\ api\t1\tbash\t:\n" +
+		"pane\tapi\t0\t0\t:-\t0\t:/code/api\t1\tbash\t:\n" +
+		"window\tapi\t1\t:editor\t1\t:*\tc3a1,120x40,0,0{60x40,0,0,1,59x40,61,0,2}\ton\n" +
+		"window\tapi\t0\t:shell\t0\t:-\t55b2,120x40,0,0,0\toff\n" +
+		"pane\tdocs\t0\t1\t:*\t0\tvm\t:/code/docs\t1\tless\t:less README.md\n" +
+		"window\tdocs\t0\t:docs\t1\t:*\t55b3,120x40,0,0,3\n" +
+		"state\tapi\tdocs\n"
+
+	expected := []importer.Entry{
+		{
+			Name:      "api",
+			Directory: "/code/api",
+			Source:    importer.SourceResurrect,
+			Template: sessiontemplate.Template{
+				Windows: []sessiontemplate.Window{
+					{
+						Name:   "shell",
+						Layout: "55b2,120x40,0,0,0",
+						Panes:  []sessiontemplate.Pane{{Directory: "/code/api", Focus: true}},
+					},
+					{
+						Name:   "editor",
+						Layout: "c3a1,120x40,0,0{60x40,0,0,1,59x40,61,0,2}",
+						Focus:  true,
+						Panes: []sessiontemplate.Pane{
+							{Directory: "/code/api", Command: "nvim main.go"},
+							{Directory: "/code/my api", Focus: true},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:      "docs",
+			Directory: "/code/docs",
+			Source:    importer.SourceResurrect,
+			Template: sessiontemplate.Template{
+				Windows: []sessiontemplate.Window{
+					{
+						Name:   "docs",
+						Layout: "55b3,120x40,0,0,3",
+						Focus:  true,
+						Panes:  []sessiontemplate.Pane{{Directory: "/code/docs", Command: "less README.md", Focus: true}},
+					},
+				},
+			},
+		},
+	}
+
+	entries, err := importer.ParseResurrect(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, entries)
+	}
+}
+
+func TestParseTmuxinator(t *testing.T) {
+	data := "name: shop\n" +
+		"root: ~/code/shop\n" +
+		"startup_window: server\n" +
+		"pre_window: nvm use\n" +
+		"windows:\n" +
+		"  - editor:\n" +
+		"      layout: main-vertical\n" +
+		"      panes:\n" +
+		"        - vim\n" +
+		"        - tests:\n" +
+		"            - cd test\n" +
+		"            - npm test\n" +
+		"  - server: npm start\n" +
+		"  - shell:\n"
+
+	expected := importer.Entry{
+		Name:      "shop",
+		Directory: "/home/user/code/shop",
+		Source:    importer.SourceTmuxinator,
+		Template: sessiontemplate.Template{
+			Windows: []sessiontemplate.Window{
+				{
+					Name:   "editor",
+					Layout: "main-vertical",
+					Panes: []sessiontemplate.Pane{
+						{Command: "nvm use; vim"},
+						{Command: "nvm use; cd test; npm test"},
+					},
+				},
+				{Name: "server", Focus: true, Panes: []sessiontemplate.Pane{{Command: "nvm use; npm start"}}},
+				{Name: "shell", Panes: []sessiontemplate.Pane{{Command: "nvm use"}}},
+			},
+		},
+	}
+
+	entry, err := importer.ParseTmuxinator([]byte(data), "/home/user")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(entry, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, entry)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := importer.ParseResurrect("state\tapi\n"); err == nil {
+		t.Error("Expected an error for a file without sessions")
+	}
+
+	if _, err := importer.ParseResurrect("pane\tapi\tx\t1\t:*\t0\tvm\t:/code\t1\tbash\t:\n"); err == nil {
+		t.Error("Expected an error for an invalid window index")
+	}
+
+	if _, err := importer.ParseTmuxinator([]byte("name: empty\n"), "/home/user"); err == nil {
+		t.Error("Expected an error for a project without windows")
+	}
+}
@@ -0,0 +1,164 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/verte-zerg/gession/internal/sessiontemplate"
+)
+
+const (
+	// Pane lines have the pane title since tmux-resurrect 3.0, older files don't have it.
+	resurrectPaneFields        = 11
+	resurrectPaneFieldsNoTitle = 10
+	resurrectWindowMinFields   = 7
+)
+
+type resurrectWindow struct {
+	index  int
+	window sessiontemplate.Window
+	panes  map[int]sessiontemplate.Pane
+}
+
+// ParseResurrect parses a tmux-resurrect save file, every saved session becomes an entry.
+//
+// Lines are tab-separated, fields prefixed with `:` may be empty:
+//
+//	pane	<session>	<window index>	<window active>	:<window flags>	<pane index>	<pane title>	:<path>	<pane active>	<command>	:<full command>
+//	window	<session>	<window index>	:<window name>	<window active>	:<window flags>	<layout>	[automatic rename]
+func ParseResurrect(data string) ([]Entry, error) {
+	sessionOrder := make([]string, 0)
+	sessions := make(map[string]map[int]*resurrectWindow)
+
+	getWindow := func(sessionName string, windowIndex int) *resurrectWindow {
+		if _, ok := sessions[sessionName]; !ok {
+			sessions[sessionName] = make(map[int]*resurrectWindow)
+			sessionOrder = append(sessionOrder, sessionName)
+		}
+
+		if _, ok := sessions[sessionName][windowIndex]; !ok {
+			sessions[sessionName][windowIndex] = &resurrectWindow{index: windowIndex, panes: make(map[int]sessiontemplate.Pane)}
+		}
+
+		return sessions[sessionName][windowIndex]
+	}
+
+	for lineIdx, line := range strings.Split(data, "\n") {
+		fields := strings.Split(line, "\t")
+
+		var err error
+
+		switch fields[0] {
+		case "pane":
+			err = parseResurrectPane(fields, getWindow)
+		case "window":
+			err = parseResurrectWindow(fields, getWindow)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineIdx+1, err)
+		}
+	}
+
+	if len(sessionOrder) == 0 {
+		return nil, errors.New("no sessions in tmux-resurrect file")
+	}
+
+	entries := make([]Entry, 0, len(sessionOrder))
+
+	for _, sessionName := range sessionOrder {
+		entries = append(entries, buildResurrectEntry(sessionName, sessions[sessionName]))
+	}
+
+	return entries, nil
+}
+
+func parseResurrectPane(fields []string, getWindow func(string, int) *resurrectWindow) error {
+	if len(fields) == resurrectPaneFieldsNoTitle {
+		// Insert an empty title, so both formats have the same field indexes
+		fields = append(fields[:6], append([]string{""}, fields[6:]...)...)
+	}
+
+	if len(fields) != resurrectPaneFields {
+		return fmt.Errorf("expected %d pane fields, got %d", resurrectPaneFields, len(fields))
+	}
+
+	windowIndex, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return fmt.Errorf("invalid window index: %w", err)
+	}
+
+	paneIndex, err := strconv.Atoi(fields[5])
+	if err != nil {
+		return fmt.Errorf("invalid pane index: %w", err)
+	}
+
+	window := getWindow(fields[1], windowIndex)
+	window.panes[paneIndex] = sessiontemplate.Pane{
+		// Spaces in paths are escaped by tmux-resurrect
+		Directory: strings.ReplaceAll(strings.TrimPrefix(fields[7], ":"), `\ `, " "),
+		Command:   strings.TrimPrefix(fields[10], ":"),
+		Focus:     fields[8] == "1",
+	}
+
+	return nil
+}
+
+func parseResurrectWindow(fields []string, getWindow func(string, int) *resurrectWindow) error {
+	if len(fields) < resurrectWindowMinFields {
+		return fmt.Errorf("expected at least %d window fields, got %d", resurrectWindowMinFields, len(fields))
+	}
+
+	windowIndex, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return fmt.Errorf("invalid window index: %w", err)
+	}
+
+	window := getWindow(fields[1], windowIndex)
+	window.window.Name = strings.TrimPrefix(fields[3], ":")
+	window.window.Focus = fields[4] == "1"
+	window.window.Layout = fields[6]
+
+	return nil
+}
+
+func buildResurrectEntry(sessionName string, windows map[int]*resurrectWindow) Entry {
+	sortedWindows := make([]*resurrectWindow, 0, len(windows))
+	for _, window := range windows {
+		sortedWindows = append(sortedWindows, window)
+	}
+
+	sort.Slice(sortedWindows, func(i, j int) bool {
+		return sortedWindows[i].index < sortedWindows[j].index
+	})
+
+	entry := Entry{
+		Name:   sessionName,
+		Source: SourceResurrect,
+	}
+
+	for _, window := range sortedWindows {
+		paneIndexes := make([]int, 0, len(window.panes))
+		for paneIndex := range window.panes {
+			paneIndexes = append(paneIndexes, paneIndex)
+		}
+
+		sort.Ints(paneIndexes)
+
+		for _, paneIndex := range paneIndexes {
+			window.window.Panes = append(window.window.Panes, window.panes[paneIndex])
+		}
+
+		// The session is started in the directory of its first pane
+		if entry.Directory == "" && len(window.window.Panes) > 0 {
+			entry.Directory = window.window.Panes[0].Directory
+		}
+
+		entry.Template.Windows = append(entry.Template.Windows, window.window)
+	}
+
+	return entry
+}
@@ -0,0 +1,167 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/verte-zerg/gession/internal/sessiontemplate"
+)
+
+// commandSeparator joins commands of a pane, tmuxinator types them one after another.
+const commandSeparator = "; "
+
+type tmuxinatorProject struct {
+	Name          string `yaml:"name"`
+	Root          string `yaml:"root"`
+	StartupWindow string `yaml:"startup_window"`
+	// PreWindow is run in every pane before its commands.
+	PreWindow yaml.Node              `yaml:"pre_window"`
+	Windows   []map[string]yaml.Node `yaml:"windows"`
+
+	// Deprecated names still supported by tmuxinator
+	ProjectName string                 `yaml:"project_name"`
+	ProjectRoot string                 `yaml:"project_root"`
+	Tabs        []map[string]yaml.Node `yaml:"tabs"`
+}
+
+type tmuxinatorWindow struct {
+	Layout string      `yaml:"layout"`
+	Root   string      `yaml:"root"`
+	Panes  []yaml.Node `yaml:"panes"`
+}
+
+// ParseTmuxinator parses a tmuxinator project, `~` in roots is replaced with the home directory.
+func ParseTmuxinator(data []byte, homeDir string) (Entry, error) {
+	project := tmuxinatorProject{}
+
+	err := yaml.Unmarshal(data, &project)
+	if err != nil {
+		return Entry{}, fmt.Errorf("could not parse tmuxinator project: %w", err)
+	}
+
+	entry := Entry{
+		Name:      firstNonEmpty(project.Name, project.ProjectName),
+		Directory: expandHome(firstNonEmpty(project.Root, project.ProjectRoot), homeDir),
+		Source:    SourceTmuxinator,
+	}
+
+	preWindow, err := nodeCommands(&project.PreWindow)
+	if err != nil {
+		return Entry{}, fmt.Errorf("pre_window: %w", err)
+	}
+
+	windows := project.Windows
+	if len(windows) == 0 {
+		windows = project.Tabs
+	}
+
+	for i, namedWindow := range windows {
+		for name, node := range namedWindow {
+			window, err := parseTmuxinatorWindow(name, &node, preWindow, homeDir)
+			if err != nil {
+				return Entry{}, fmt.Errorf("window %d: %w", i, err)
+			}
+
+			window.Focus = name == project.StartupWindow
+			entry.Template.Windows = append(entry.Template.Windows, window)
+		}
+	}
+
+	err = entry.Template.Validate()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return entry, nil
+}
+
+// parseTmuxinatorWindow parses a window written as commands of a single pane, or as a mapping with panes and layout.
+func parseTmuxinatorWindow(name string, node *yaml.Node, preWindow []string, homeDir string) (sessiontemplate.Window, error) {
+	window := sessiontemplate.Window{Name: name}
+
+	if node.Kind != yaml.MappingNode {
+		commands, err := nodeCommands(node)
+		if err != nil {
+			return window, err
+		}
+
+		window.Panes = []sessiontemplate.Pane{{Command: joinCommands(preWindow, commands)}}
+
+		return window, nil
+	}
+
+	definition := tmuxinatorWindow{}
+
+	err := node.Decode(&definition)
+	if err != nil {
+		return window, fmt.Errorf("could not parse window: %w", err)
+	}
+
+	window.Layout = definition.Layout
+	window.Directory = expandHome(definition.Root, homeDir)
+
+	for _, paneNode := range definition.Panes {
+		// A pane may be named: `- server: [bundle exec rails s]`
+		if paneNode.Kind == yaml.MappingNode && len(paneNode.Content) == 2 { //nolint:mnd
+			paneNode = *paneNode.Content[1]
+		}
+
+		commands, err := nodeCommands(&paneNode)
+		if err != nil {
+			return window, err
+		}
+
+		window.Panes = append(window.Panes, sessiontemplate.Pane{Command: joinCommands(preWindow, commands)})
+	}
+
+	if len(window.Panes) == 0 {
+		window.Panes = []sessiontemplate.Pane{{Command: joinCommands(preWindow, nil)}}
+	}
+
+	return window, nil
+}
+
+// nodeCommands returns commands written as a single string, a list of strings or nothing.
+func nodeCommands(node *yaml.Node) ([]string, error) {
+	switch node.Kind {
+	case 0:
+		return nil, nil
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" || node.Value == "" {
+			return nil, nil
+		}
+
+		return []string{node.Value}, nil
+	case yaml.SequenceNode:
+		commands := make([]string, 0, len(node.Content))
+
+		for _, commandNode := range node.Content {
+			if commandNode.Kind != yaml.ScalarNode {
+				return nil, errors.New("commands should be strings")
+			}
+
+			commands = append(commands, commandNode.Value)
+		}
+
+		return commands, nil
+	default:
+		return nil, fmt.Errorf("unexpected commands at line %d", node.Line)
+	}
+}
+
+func joinCommands(preWindow, commands []string) string {
+	return strings.Join(append(append([]string{}, preWindow...), commands...), commandSeparator)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -28,6 +28,9 @@ const (
 	CtrlR     Special = "CtrlR"
 	CtrlT     Special = "CtrlT"
 	CtrlE     Special = "CtrlE"
+	CtrlW     Special = "CtrlW"
+	CtrlZ     Special = "CtrlZ"
+	CtrlF     Special = "CtrlF"
 
 	escChar       byte = 27
 	backspaceChar byte = 127
@@ -38,6 +41,9 @@ const (
 	ctrlRChar     byte = 18
 	ctrlTChar     byte = 20
 	ctrlEChar     byte = 5
+	ctrlWChar     byte = 23
+	ctrlZChar     byte = 26
+	ctrlFChar     byte = 6
 
 	controlSeqLen = 3
 )
@@ -73,6 +79,12 @@ func New(buf []byte, size int) Key {
 			return Key{SpecialKey: CtrlT}
 		case ctrlEChar:
 			return Key{SpecialKey: CtrlE}
+		case ctrlWChar:
+			return Key{SpecialKey: CtrlW}
+		case ctrlZChar:
+			return Key{SpecialKey: CtrlZ}
+		case ctrlFChar:
+			return Key{SpecialKey: CtrlF}
 		default:
 			value := []rune(string(char))[0]
 			if unicode.IsPrint(value) {
@@ -22,6 +22,8 @@ const (
 
 type Keyboard struct {
 	outputEventCh chan event.Event
+
+	terminalState *term.State
 }
 
 func NewKeyboard() *Keyboard {
@@ -33,15 +35,6 @@ func (k *Keyboard) SetOutputCh(outputEventCh chan event.Event) {
 }
 
 func (k *Keyboard) captureKeys() {
-	fd := int(os.Stdin.Fd())
-	t, err := term.MakeRaw(fd)
-	assert.Assert(err == nil, "could not make raw terminal")
-
-	defer func() {
-		err := term.Restore(fd, t)
-		assert.Assert(err == nil, "could not restore terminal")
-	}()
-
 	b := make([]byte, MaxKeyLength)
 
 	for {
@@ -66,5 +59,21 @@ func (k *Keyboard) captureKeys() {
 }
 
 func (k *Keyboard) Start() {
+	fd := int(os.Stdin.Fd())
+	t, err := term.MakeRaw(fd)
+	assert.Assert(err == nil, "could not make raw terminal")
+
+	k.terminalState = t
+
 	go k.captureKeys()
 }
+
+// Restore returns the terminal to the state it had before the keyboard was started.
+func (k *Keyboard) Restore() {
+	if k.terminalState == nil {
+		return
+	}
+
+	err := term.Restore(int(os.Stdin.Fd()), k.terminalState)
+	assert.Assert(err == nil, "could not restore terminal")
+}
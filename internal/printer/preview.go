@@ -0,0 +1,193 @@
+package printer
+
+import (
+	"strings"
+
+	"github.com/verte-zerg/gession/internal/session"
+	"github.com/verte-zerg/gession/pkg/ansi"
+)
+
+type direction uint8
+
+const (
+	up direction = 1 << iota
+	down
+	left
+	right
+)
+
+var boxChars = map[direction]string{
+	left:                     "─",
+	right:                    "─",
+	left | right:             "─",
+	up:                       "│",
+	down:                     "│",
+	up | down:                "│",
+	down | right:             "┌",
+	down | left:              "┐",
+	up | right:               "└",
+	up | left:                "┘",
+	up | down | right:        "├",
+	up | down | left:         "┤",
+	left | right | down:      "┬",
+	left | right | up:        "┴",
+	up | down | left | right: "┼",
+}
+
+// paneBox is a pane scaled into the preview, coordinates are the positions of its borders.
+type paneBox struct {
+	pane           session.Pane
+	x0, y0, x1, y1 int
+}
+
+// windowGrid draws the panes of a window scaled into a box with the given size, borders included.
+type windowGrid struct {
+	width, height int
+	borders       [][]direction
+	boxes         []paneBox
+}
+
+func newWindowGrid(panes []session.Pane, width, height int) *windowGrid {
+	grid := &windowGrid{
+		width:   width,
+		height:  height,
+		borders: make([][]direction, height),
+	}
+
+	for y := range grid.borders {
+		grid.borders[y] = make([]direction, width)
+	}
+
+	panes = withGeometry(panes)
+
+	windowWidth, windowHeight := 0, 0
+	for _, pane := range panes {
+		windowWidth = max(windowWidth, pane.Left+pane.Width)
+		windowHeight = max(windowHeight, pane.Top+pane.Height)
+	}
+
+	// Border cells surround every pane in tmux, a pane starting at 0 has its border at -1 and a pane ending at the
+	// window edge has its border at the window size. These coordinates are mapped to the first and last cell of the box.
+	scale := func(border, windowSize, boxSize int) int {
+		return ((border+1)*(boxSize-1)*2 + windowSize + 1) / (2 * (windowSize + 1)) //nolint:mnd
+	}
+
+	for _, pane := range panes {
+		box := paneBox{
+			pane: pane,
+			x0:   scale(pane.Left-1, windowWidth, width),
+			x1:   scale(pane.Left+pane.Width, windowWidth, width),
+			y0:   scale(pane.Top-1, windowHeight, height),
+			y1:   scale(pane.Top+pane.Height, windowHeight, height),
+		}
+
+		grid.drawHorizontal(box.y0, box.x0, box.x1)
+		grid.drawHorizontal(box.y1, box.x0, box.x1)
+		grid.drawVertical(box.x0, box.y0, box.y1)
+		grid.drawVertical(box.x1, box.y0, box.y1)
+
+		grid.boxes = append(grid.boxes, box)
+	}
+
+	return grid
+}
+
+// withGeometry returns panes with their geometry. Panes without known geometry are placed side by side.
+func withGeometry(panes []session.Pane) []session.Pane {
+	for _, pane := range panes {
+		if pane.Width == 0 || pane.Height == 0 {
+			placedPanes := make([]session.Pane, len(panes))
+
+			for i, pane := range panes {
+				pane.Left, pane.Top, pane.Width, pane.Height = i*2, 0, 1, 1 //nolint:mnd
+				placedPanes[i] = pane
+			}
+
+			return placedPanes
+		}
+	}
+
+	return panes
+}
+
+func (g *windowGrid) drawHorizontal(y, x0, x1 int) {
+	for x := x0; x <= x1; x++ {
+		if x > x0 {
+			g.borders[y][x] |= left
+		}
+
+		if x < x1 {
+			g.borders[y][x] |= right
+		}
+	}
+}
+
+func (g *windowGrid) drawVertical(x, y0, y1 int) {
+	for y := y0; y <= y1; y++ {
+		if y > y0 {
+			g.borders[y][x] |= up
+		}
+
+		if y < y1 {
+			g.borders[y][x] |= down
+		}
+	}
+}
+
+// lines renders the grid with the pane snapshots cut into their boxes.
+func (g *windowGrid) lines() []string {
+	snapshots := make([][]ansi.Line, len(g.boxes))
+
+	for i, box := range g.boxes {
+		snapshots[i] = ansi.CutSnapshot(box.pane.Snapshot, max(0, box.x1-box.x0-1), max(0, box.y1-box.y0-1))
+	}
+
+	lines := make([]string, g.height)
+
+	for y := range g.height {
+		builder := strings.Builder{}
+
+		for x := 0; x < g.width; x++ {
+			if g.borders[y][x] != 0 {
+				builder.WriteString(reset + boxChars[g.borders[y][x]])
+
+				continue
+			}
+
+			boxIdx := g.boxStartingAt(x, y)
+			if boxIdx == -1 {
+				builder.WriteString(" ")
+
+				continue
+			}
+
+			box := g.boxes[boxIdx]
+			contentWidth := box.x1 - box.x0 - 1
+			row := y - box.y0 - 1
+
+			if row < len(snapshots[boxIdx]) {
+				builder.WriteString(snapshots[boxIdx][row].Content + reset)
+				builder.WriteString(strings.Repeat(" ", contentWidth-snapshots[boxIdx][row].Len))
+			} else {
+				builder.WriteString(strings.Repeat(" ", contentWidth))
+			}
+
+			x += contentWidth - 1
+		}
+
+		lines[y] = builder.String()
+	}
+
+	return lines
+}
+
+// boxStartingAt returns the index of the box whose content starts at the cell, or -1.
+func (g *windowGrid) boxStartingAt(x, y int) int {
+	for i, box := range g.boxes {
+		if box.x0+1 == x && box.x0+1 < box.x1 && box.y0 < y && y < box.y1 {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,120 @@
+package printer
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/verte-zerg/gession/internal/session"
+	"github.com/verte-zerg/gession/pkg/ansi"
+)
+
+var sgrRegexp = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func stripStyles(lines []string) []string {
+	stripped := make([]string, len(lines))
+	for i, line := range lines {
+		stripped[i] = sgrRegexp.ReplaceAllString(line, "")
+	}
+
+	return stripped
+}
+
+func TestWindowGridLayouts(t *testing.T) {
+	tests := []struct {
+		name     string
+		panes    []session.Pane
+		width    int
+		height   int
+		expected []string
+	}{
+		{
+			name: "Single pane",
+			panes: []session.Pane{
+				{Width: 80, Height: 24},
+			},
+			width:  6,
+			height: 3,
+			expected: []string{
+				"┌────┐",
+				"│    │",
+				"└────┘",
+			},
+		},
+		{
+			name: "70/30 horizontal split",
+			panes: []session.Pane{
+				{Width: 69, Height: 24},
+				{Left: 70, Width: 30, Height: 24},
+			},
+			width:  11,
+			height: 3,
+			expected: []string{
+				"┌──────┬──┐",
+				"│      │  │",
+				"└──────┴──┘",
+			},
+		},
+		{
+			name: "Nested splits",
+			panes: []session.Pane{
+				{Width: 39, Height: 24},
+				{Left: 40, Width: 39, Height: 11},
+				{Left: 40, Top: 12, Width: 39, Height: 12},
+			},
+			width:  9,
+			height: 5,
+			expected: []string{
+				"┌───┬───┐",
+				"│   │   │",
+				"│   ├───┤",
+				"│   │   │",
+				"└───┴───┘",
+			},
+		},
+		{
+			name: "Unknown geometry falls back to columns",
+			panes: []session.Pane{
+				{},
+				{},
+			},
+			width:  7,
+			height: 3,
+			expected: []string{
+				"┌──┬──┐",
+				"│  │  │",
+				"└──┴──┘",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := stripStyles(newWindowGrid(tt.panes, tt.width, tt.height).lines())
+
+			if strings.Join(lines, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("Expected grid\n%s\ngot\n%s", strings.Join(tt.expected, "\n"), strings.Join(lines, "\n"))
+			}
+		})
+	}
+}
+
+func TestWindowGridSnapshots(t *testing.T) {
+	left := "left pane\nsecond line"
+	right := "right"
+
+	lines := newWindowGrid([]session.Pane{
+		{Width: 10, Height: 5, Snapshot: &left},
+		{Left: 11, Width: 10, Height: 5, Snapshot: &right},
+	}, 13, 4).lines()
+
+	for _, line := range lines {
+		if visibleLen := ansi.CalculateVisibleLen(line); visibleLen != 13 {
+			t.Errorf("Expected every line to be 13 cells wide, got %d in `%s`", visibleLen, line)
+		}
+	}
+
+	if got := stripStyles(lines)[1]; got != "│left │right│" {
+		t.Errorf("Expected snapshots to be cut into their panes, got `%s`", got)
+	}
+}
@@ -19,6 +19,10 @@ const (
 	hotkeyDescription = "\033[38;5;240m"
 	hotkeyKey         = "\033[38;5;239m"
 	hotkeySeparator   = "\033[38;5;238m"
+	statusMessage     = "\033[31m"
+	gitStatus         = "\033[38;5;109m"
+	gitDirty          = "\033[38;5;179m"
+	hibernated        = "\033[38;5;245m"
 
 	// TEXT EFFECTS.
 	reset     = "\033[0m"
@@ -31,7 +35,8 @@ const (
 	jumpCell   = "\033[%dA\033[%dG"
 
 	// TUI.
-	footerHeight = 3
+	footerHeight   = 3
+	minPreviewSize = 3
 )
 
 var (
@@ -40,6 +45,8 @@ var (
 		{"<c-e>", "delete"},
 		{"<c-r>", "rename"},
 		{"<c-t>", "new"},
+		{"<c-z>", "hibernate"},
+		{"<c-f>", "search content"},
 		{"←/→", "wrap/unwrap"},
 		{"↑/↓/tab/<s-tab>", "move"},
 		{"enter", "select/create"},
@@ -47,10 +54,18 @@ var (
 	footerPrimeHotkeys = [][2]string{
 		{"<c-c/d>", "exit"},
 		{"↑/↓/tab/<s-tab>", "move"},
+		{"←/→", "wrap/unwrap"},
 		{"enter", "select/create"},
+		{"<c-w>", "worktree"},
+	}
+	footerSearchHotkeys = [][2]string{
+		{"esc", "back"},
+		{"↑/↓/tab/<s-tab>", "move"},
+		{"enter", "jump"},
 	}
 	normalFooter = newFooter(footerHotkeys)
 	primeFooter  = newFooter(footerPrimeHotkeys)
+	searchFooter = newFooter(footerSearchHotkeys)
 )
 
 type footer struct {
@@ -107,7 +122,7 @@ func New(width, height int, prime bool) *Printer {
 	return &Printer{width, height, prime}
 }
 
-func (p Printer) GenerateFrame(vTree *sessiontree.VisualizeTree, input string) string {
+func (p Printer) GenerateFrame(vTree *sessiontree.VisualizeTree, input, status string) string {
 	frame := "\033[H"
 
 	selectedSession := vTree.GetSelectedSession()
@@ -117,7 +132,7 @@ func (p Printer) GenerateFrame(vTree *sessiontree.VisualizeTree, input string) s
 
 	restHeight := p.height
 
-	if selectedSession != nil && !p.prime {
+	if selectedSession != nil && !p.prime && len(selectedSession.Windows) > 0 {
 		previewHeight := p.height / 2 //nolint:mnd
 		restHeight = p.height - previewHeight
 
@@ -132,67 +147,41 @@ func (p Printer) GenerateFrame(vTree *sessiontree.VisualizeTree, input string) s
 
 	frame += p.generateEmptyLines(restHeight - rows - footerHeight)
 	frame += p.generateSessionsRepresentation(vTree, restHeight)
-	frame += p.generateFooter(filteredSessionsCount, vTree.GetSessionsCount(), input)
+	hotkeys := normalFooter
+	if p.prime {
+		hotkeys = primeFooter
+	}
+
+	stats := fmt.Sprintf("sessions: %d/%d", filteredSessionsCount, vTree.GetSessionsCount())
+	frame += p.generateFooter(stats, input, status, hotkeys)
 
 	return hideCursor + frame + showCursor
 }
 
+// generateSessionPreview draws the selected window, or the active window of the session, mirroring its real layout.
 func (p Printer) generateSessionPreview(session sessiontree.FilteredSession, windowID *string, height, width int) string {
-	panesSnapshots := make([]*string, 0)
+	var previewWindow *sessiontree.FilteredWindow
 
 	for _, window := range session.FilteredChildren {
-		if windowID == nil || window.ID == *windowID {
-			for _, pane := range window.FilteredChildren {
-				panesSnapshots = append(panesSnapshots, pane.Snapshot)
-			}
-		}
-	}
+		if windowID != nil && window.ID == *windowID {
+			previewWindow = window
 
-	separatorsCount := len(panesSnapshots) + 1
-	contentWidth := width - separatorsCount
-	contentHeight := height - footerHeight
-	widths := make([]int, len(panesSnapshots))
-	defaultWidth := contentWidth / len(panesSnapshots)
-	restWidth := contentWidth % len(panesSnapshots)
-
-	for i := range widths {
-		widths[i] = defaultWidth
-		if i < restWidth {
-			widths[i]++
+			break
 		}
-	}
 
-	snapshotsLines := make([][]ansi.Line, 0)
-
-	for i, snapshot := range panesSnapshots {
-		cuttedSnapshotLines := ansi.CutSnapshot(snapshot, widths[i], contentHeight)
-		snapshotsLines = append(snapshotsLines, cuttedSnapshotLines)
-	}
-
-	DEL := reset + "│"
-
-	lines := make([]string, contentHeight+footerHeight)
-
-	for i := range contentHeight {
-		for j, cuttedSnapshotLines := range snapshotsLines {
-			if i < len(cuttedSnapshotLines) {
-				lines[i+1] += DEL + cuttedSnapshotLines[i].Content + strings.Repeat(" ", widths[j]-cuttedSnapshotLines[i].Len)
-			} else {
-				lines[i+1] += DEL + strings.Repeat(" ", widths[j])
-			}
+		if previewWindow == nil || (windowID == nil && window.IsActive) {
+			previewWindow = window
 		}
-
-		lines[i+1] += DEL
 	}
 
-	lineDashParts := make([]string, 0)
+	// The last line is kept empty to separate the preview from the list
+	lines := make([]string, height-1, height)
 
-	for _, width := range widths {
-		lineDashParts = append(lineDashParts, strings.Repeat("─", width))
+	if previewWindow != nil && len(previewWindow.Panes) != 0 && height > minPreviewSize && width > minPreviewSize {
+		lines = newWindowGrid(previewWindow.Panes, width, height-1).lines()
 	}
 
-	lines[0] = reset + "┌" + strings.Join(lineDashParts, "┬") + "┐"
-	lines[contentHeight+1] = reset + "└" + strings.Join(lineDashParts, "┴") + "┘"
+	lines = append(lines, "")
 
 	return strings.Join(lines, clearLine+"\r\n") + "\r\n"
 }
@@ -253,10 +242,13 @@ func (p Printer) generateSessionRepresentation(
 ) string {
 	var line, unwrapChar string
 
+	// Sessions without windows, like prime folders without worktrees, are padded to stay aligned
 	switch {
-	case session.IsUnwrapped && !p.prime:
+	case len(session.Windows) == 0:
+		unwrapChar = "  "
+	case session.IsUnwrapped:
 		unwrapChar = "- "
-	case !session.IsUnwrapped && !p.prime:
+	default:
 		unwrapChar = "+ "
 	}
 
@@ -266,12 +258,22 @@ func (p Printer) generateSessionRepresentation(
 		line = fmt.Sprintf("  %s%s", unwrapChar, session.GetString(false))
 	}
 
+	line += p.generateGitStatus(session)
+
 	if session.IsAttached {
 		line += " (attached)"
 	}
 
-	if p.prime && !strings.HasPrefix(session.ID, "notexisted_") {
-		line += " (existed)"
+	if p.prime && session.LinkedSession != nil {
+		line += " (session: " + session.LinkedSession.Name + ")"
+	}
+
+	if session.Source != "" && session.LinkedSession == nil {
+		line += " (" + session.Source + ")"
+	}
+
+	if session.IsHibernated {
+		line += " " + hibernated + "(hibernated)" + reset
 	}
 
 	line += clearLine + "\r\n"
@@ -279,6 +281,30 @@ func (p Printer) generateSessionRepresentation(
 	return line
 }
 
+// generateGitStatus renders the git ref, ahead/behind counts and the dirty marker, e.g. ` [main ↑2 ↓1 *]`.
+func (p Printer) generateGitStatus(session *sessiontree.FilteredSession) string {
+	status := session.GitStatus
+	if status == nil {
+		return ""
+	}
+
+	line := " " + gitStatus + "[" + reset + session.GetGitRefString() + gitStatus
+
+	if status.Ahead > 0 {
+		line += fmt.Sprintf(" ↑%d", status.Ahead)
+	}
+
+	if status.Behind > 0 {
+		line += fmt.Sprintf(" ↓%d", status.Behind)
+	}
+
+	if status.IsDirty {
+		line += gitDirty + " *" + gitStatus
+	}
+
+	return line + "]" + reset
+}
+
 func (p Printer) generateSessionsRepresentation(vTree *sessiontree.VisualizeTree, height int) string {
 	sessions := vTree.GetSessions()
 	selected := vTree.GetSelectedIdx()
@@ -312,15 +338,17 @@ func (p Printer) generateSessionsRepresentation(vTree *sessiontree.VisualizeTree
 	return strings.Join(lines, "")
 }
 
-func (p Printer) generateFooter(count, total int, input string) string {
-	frame := prompt + input + reset + clearLine + "\r\n"
-	frame += sessionStats + fmt.Sprintf("sessions: %d/%d", count, total) + reset + clearLine + "\r\n"
-	hotkeyList := normalFooter.String(p.width)
+func (p Printer) generateFooter(stats, input, status string, hotkeys *footer) string {
+	stats = sessionStats + stats + reset
 
-	if p.prime {
-		hotkeyList = primeFooter.String(p.width)
+	if status != "" {
+		stats += " " + statusMessage + ansi.CutString(status, p.width-ansi.CalculateVisibleLen(stats)-1).Content + reset
 	}
 
+	frame := prompt + input + reset + clearLine + "\r\n"
+	frame += stats + clearLine + "\r\n"
+	hotkeyList := hotkeys.String(p.width)
+
 	frame += hotkeyDescription + hotkeyList + clearLine + reset + relativelyJumpToCell(footerHeight-1, utf8.RuneCountInString(input)+1)
 
 	return frame
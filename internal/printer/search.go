@@ -0,0 +1,110 @@
+package printer
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/verte-zerg/gession/internal/contentsearch"
+	"github.com/verte-zerg/gession/pkg/ansi"
+	"github.com/verte-zerg/gession/pkg/fuzzy"
+)
+
+// GenerateSearchFrame draws the content search: the pane of the selected hit scrolled to it and the list of hits.
+func (p Printer) GenerateSearchFrame(hits []contentsearch.Hit, selected, indexed int, input, status string) string {
+	frame := "\033[H"
+
+	previewHeight := p.height / 2 //nolint:mnd
+	listHeight := p.height - previewHeight - footerHeight
+
+	if selected < len(hits) && previewHeight > minPreviewSize {
+		frame += p.generateHitPreview(hits[selected], previewHeight)
+	} else {
+		frame += p.generateEmptyLines(previewHeight)
+	}
+
+	frame += p.generateEmptyLines(listHeight - len(hits))
+	frame += p.generateHitsRepresentation(hits, selected, listHeight)
+	frame += p.generateFooter(fmt.Sprintf("panes: %d/%d", len(hits), indexed), input, status, searchFooter)
+
+	return hideCursor + frame + showCursor
+}
+
+// generateHitPreview draws the lines of the pane around the hit, the hit is in the middle if there are lines to fill the preview.
+func (p Printer) generateHitPreview(hit contentsearch.Hit, height int) string {
+	lines := hit.Pane.Lines
+	// The last line is kept empty to separate the preview from the list
+	rows := height - 1
+
+	from := max(0, min(hit.Line-rows/2, len(lines)-rows)) //nolint:mnd
+	to := min(len(lines), from+rows)
+
+	previewLines := make([]string, 0, height)
+
+	for lineIdx := from; lineIdx < to; lineIdx++ {
+		line := lines[lineIdx]
+		if lineIdx == hit.Line {
+			line = bold + highlightPositions(line, hit.Positions, bold)
+		}
+
+		previewLines = append(previewLines, ansi.CutString(line, p.width).Content+reset)
+	}
+
+	for len(previewLines) < height {
+		previewLines = append(previewLines, "")
+	}
+
+	return strings.Join(previewLines, clearLine+"\r\n") + clearLine + "\r\n"
+}
+
+func (p Printer) generateHitsRepresentation(hits []contentsearch.Hit, selected, height int) string {
+	displayFrom := max(0, (selected+1)-height)
+	displayTo := min(len(hits), displayFrom+height)
+
+	lines := make([]string, 0, displayTo-displayFrom)
+
+	for hitIdx := displayFrom; hitIdx < displayTo; hitIdx++ {
+		hit := hits[hitIdx]
+		label := hit.Pane.SessionName + ":" + hit.Pane.WindowName + "." + strconv.Itoa(hit.Pane.Index)
+
+		// Indentation of the line isn't shown, so more of the matched text fits
+		line := []rune(hit.Pane.Lines[hit.Line])
+		indent := len(line) - len(strings.TrimLeftFunc(string(line), unicode.IsSpace))
+		positions := make([]int, 0, len(hit.Positions))
+
+		for _, position := range hit.Positions {
+			positions = append(positions, position-indent)
+		}
+
+		text := gitStatus + label + reset + " " + highlightPositions(string(line[indent:]), positions, "")
+
+		if hitIdx == selected {
+			text = fmt.Sprintf("%s%s>%s %s", cursor, bold, reset, text)
+		} else {
+			text = "  " + text
+		}
+
+		lines = append(lines, ansi.CutString(text, p.width).Content+reset+clearLine+"\r\n")
+	}
+
+	slices.Reverse(lines)
+
+	return strings.Join(lines, "")
+}
+
+// highlightPositions colors the chars at the positions, the style is restored after every highlighted char.
+func highlightPositions(line string, positions []int, style string) string {
+	builder := strings.Builder{}
+
+	for _, text := range fuzzy.Colorize(line, positions) {
+		if text.Highlighted {
+			builder.WriteString(highlight + text.Text + reset + style)
+		} else {
+			builder.WriteString(text.Text)
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,505 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/verte-zerg/gession/internal/session"
+	"github.com/verte-zerg/gession/pkg/fuzzy"
+)
+
+// Field is what a term is matched against.
+type Field int
+
+const (
+	// FieldName is the session name, or its git ref if the name doesn't match.
+	FieldName Field = iota
+	// FieldDirectory is the session directory, `dir:`.
+	FieldDirectory
+	// FieldTag is one of the session tags, `tag:`: attached, hibernated, git, dirty or the source of an imported session.
+	FieldTag
+	// FieldWindow is the window name, `win:`.
+	FieldWindow
+	// FieldCommand is the command running in the pane, `cmd:`.
+	FieldCommand
+)
+
+// Kind is how the pattern of a term is matched.
+type Kind int
+
+const (
+	KindFuzzy Kind = iota
+	// KindExact matches a substring, `'term`, negated terms are exact by default.
+	KindExact
+	// KindPrefix matches the start, `^term`.
+	KindPrefix
+	// KindSuffix matches the end, `term$`.
+	KindSuffix
+	// KindEqual matches the whole text, `^term$`.
+	KindEqual
+)
+
+// level is the depth of the tree a field belongs to.
+type level int
+
+const (
+	levelSession level = iota
+	levelWindow
+	levelPane
+)
+
+// positionalFields are the fields of the plain terms in the `session window pane` form.
+var positionalFields = []Field{FieldName, FieldWindow, FieldCommand}
+
+var qualifiers = map[string]Field{
+	"dir:": FieldDirectory,
+	"tag:": FieldTag,
+	"win:": FieldWindow,
+	"cmd:": FieldCommand,
+}
+
+type Term struct {
+	Field   Field
+	Kind    Kind
+	Pattern string
+	Negated bool
+}
+
+// Query is a conjunction of groups, a group matches if any of its terms matches.
+type Query struct {
+	Groups [][]Term
+}
+
+// Result is a match of an entity, positions are highlighted in its name.
+type Result struct {
+	Score     int
+	Positions []int
+}
+
+// SessionResult is a match of a session, the git ref is highlighted if the session is found by it.
+type SessionResult struct {
+	Result
+
+	RefPositions []int
+}
+
+// Parse parses the query. Terms separated by spaces all have to match, terms joined with `|` are alternatives:
+//
+//	api !test         sessions matching `api` and not containing `test`
+//	dir:code win:vim  sessions in `code` directories with a `vim` window
+//	^web | api$       sessions starting with `web` or ending with `api`
+//
+// Terms without a pattern, e.g. `dir:` while it's being typed, are skipped.
+//
+// Two or more plain terms, without qualifiers, modifiers and `|`, keep the positional form `session window pane`:
+// `api editor nvim` finds `nvim` panes in `editor` windows of `api` sessions, an empty part matches everything.
+// Plain terms after the third one are ignored, like before the query syntax.
+func Parse(input string) Query {
+	if query, ok := parsePositional(input); ok {
+		return query
+	}
+
+	query := Query{}
+	isAlternative := false
+
+	for _, token := range strings.Fields(strings.ReplaceAll(input, "|", " | ")) {
+		if token == "|" {
+			isAlternative = len(query.Groups) > 0
+
+			continue
+		}
+
+		term, ok := parseTerm(token)
+		if !ok {
+			continue
+		}
+
+		if isAlternative {
+			last := len(query.Groups) - 1
+			query.Groups[last] = append(query.Groups[last], term)
+		} else {
+			query.Groups = append(query.Groups, []Term{term})
+		}
+
+		isAlternative = false
+	}
+
+	return query
+}
+
+// parsePositional parses the `session window pane` form, it reports false if the input uses the query syntax.
+func parsePositional(input string) (Query, bool) {
+	parts := strings.Split(strings.TrimSpace(input), " ")
+	if len(parts) < 2 || strings.Contains(input, "|") {
+		return Query{}, false
+	}
+
+	query := Query{}
+
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+
+		term, _ := parseTerm(part)
+		if term.Field != FieldName || term.Kind != KindFuzzy || term.Negated {
+			return Query{}, false
+		}
+
+		if i < len(positionalFields) {
+			term.Field = positionalFields[i]
+			query.Groups = append(query.Groups, []Term{term})
+		}
+	}
+
+	return query, true
+}
+
+func parseTerm(token string) (Term, bool) {
+	term := Term{Field: FieldName, Kind: KindFuzzy}
+
+	token, term.Negated = strings.CutPrefix(token, "!")
+
+	for qualifier, field := range qualifiers {
+		if rest, ok := strings.CutPrefix(token, qualifier); ok {
+			token = rest
+			term.Field = field
+
+			break
+		}
+	}
+
+	// Negation may be written after the qualifier too: `dir:!tmp`
+	if rest, ok := strings.CutPrefix(token, "!"); ok {
+		token = rest
+		term.Negated = !term.Negated
+	}
+
+	// A fuzzy negation would exclude almost everything, so negated terms are matched exactly
+	if term.Negated {
+		term.Kind = KindExact
+	}
+
+	isPrefix := strings.HasPrefix(token, "^")
+	isSuffix := len(token) > 1 && strings.HasSuffix(token, "$")
+
+	switch {
+	case strings.HasPrefix(token, "'"):
+		term.Kind = KindExact
+		token = token[1:]
+	case isPrefix && isSuffix:
+		term.Kind = KindEqual
+		token = token[1 : len(token)-1]
+	case isPrefix:
+		term.Kind = KindPrefix
+		token = token[1:]
+	case isSuffix:
+		term.Kind = KindSuffix
+		token = token[:len(token)-1]
+	}
+
+	term.Pattern = token
+
+	return term, token != ""
+}
+
+// IsEmpty reports whether the query matches everything.
+func (q Query) IsEmpty() bool {
+	return len(q.Groups) == 0
+}
+
+// MatchSession reports whether the session matches the query, window and pane terms match if any of its windows or panes does.
+func (q Query) MatchSession(s *session.Session) (SessionResult, bool) {
+	result := SessionResult{}
+
+	for _, group := range q.Groups {
+		groupResult := SessionResult{}
+		isMatched := false
+
+		for _, term := range group {
+			termResult := SessionResult{}
+			ok := false
+
+			switch term.Field.level() {
+			case levelSession:
+				termResult, ok = matchSessionTerm(term, s)
+			case levelWindow, levelPane:
+				ok = anyWindow(term, s) != term.Negated
+			}
+
+			if ok {
+				isMatched = true
+				groupResult.merge(termResult)
+			}
+		}
+
+		if !isMatched {
+			return SessionResult{}, false
+		}
+
+		result.merge(groupResult)
+	}
+
+	return result, true
+}
+
+// MatchWindow reports whether the window matches the groups with window and pane terms.
+// Session terms in these groups are matched against the session, pane terms match if any pane of the window does.
+func (q Query) MatchWindow(s *session.Session, w *session.Window) (Result, bool) {
+	result := Result{}
+
+	for _, group := range q.groupsDownTo(levelWindow) {
+		groupResult := Result{}
+		isMatched := false
+
+		for _, term := range group {
+			termResult := Result{}
+			ok := false
+
+			switch term.Field.level() {
+			case levelSession:
+				_, ok = matchSessionTerm(term, s)
+			case levelWindow:
+				termResult, ok = matchTerm(term, w.Name)
+			case levelPane:
+				ok = anyPane(term, w) != term.Negated
+			}
+
+			if ok {
+				isMatched = true
+				groupResult.merge(termResult)
+			}
+		}
+
+		if !isMatched {
+			return Result{}, false
+		}
+
+		result.merge(groupResult)
+	}
+
+	return result, true
+}
+
+// MatchPane reports whether the pane matches the groups with pane terms.
+// Session and window terms in these groups are matched against the session and window.
+func (q Query) MatchPane(s *session.Session, w *session.Window, p *session.Pane) (Result, bool) {
+	result := Result{}
+
+	for _, group := range q.groupsDownTo(levelPane) {
+		groupResult := Result{}
+		isMatched := false
+
+		for _, term := range group {
+			termResult := Result{}
+			ok := false
+
+			switch term.Field.level() {
+			case levelSession:
+				_, ok = matchSessionTerm(term, s)
+			case levelWindow:
+				_, ok = matchTerm(term, w.Name)
+			case levelPane:
+				termResult, ok = matchTerm(term, p.CurrentCommand)
+			}
+
+			if ok {
+				isMatched = true
+				groupResult.merge(termResult)
+			}
+		}
+
+		if !isMatched {
+			return Result{}, false
+		}
+
+		result.merge(groupResult)
+	}
+
+	return result, true
+}
+
+// groupsDownTo returns the groups with terms at the level or deeper, other groups are matched by the parent entities.
+func (q Query) groupsDownTo(minLevel level) [][]Term {
+	groups := make([][]Term, 0, len(q.Groups))
+
+	for _, group := range q.Groups {
+		for _, term := range group {
+			if term.Field.level() >= minLevel {
+				groups = append(groups, group)
+
+				break
+			}
+		}
+	}
+
+	return groups
+}
+
+func (f Field) level() level {
+	//nolint:exhaustive
+	switch f {
+	case FieldWindow:
+		return levelWindow
+	case FieldCommand:
+		return levelPane
+	}
+
+	return levelSession
+}
+
+func matchSessionTerm(term Term, s *session.Session) (SessionResult, bool) {
+	//nolint:exhaustive
+	switch term.Field {
+	case FieldDirectory:
+		result, ok := matchTerm(term, s.Directory)
+
+		// The directory isn't highlighted, it isn't shown
+		return SessionResult{Result: Result{Score: result.Score}}, ok
+	case FieldTag:
+		return SessionResult{}, anyText(term, Tags(s)) != term.Negated
+	}
+
+	if result, ok := matchTerm(term, s.Name); ok || term.Negated {
+		return SessionResult{Result: result}, ok
+	}
+
+	if s.GitStatus == nil {
+		return SessionResult{}, false
+	}
+
+	result, ok := matchTerm(term, s.GitStatus.Ref())
+
+	return SessionResult{Result: Result{Score: result.Score}, RefPositions: result.Positions}, ok
+}
+
+// matchTerm matches the text, a negated term matches if the pattern doesn't and has nothing to highlight.
+func matchTerm(term Term, text string) (Result, bool) {
+	var match func(string, string) (fuzzy.Result, bool)
+
+	switch term.Kind {
+	case KindExact:
+		match = fuzzy.MatchExact
+	case KindPrefix:
+		match = fuzzy.MatchPrefix
+	case KindSuffix:
+		match = fuzzy.MatchSuffix
+	case KindEqual:
+		match = fuzzy.MatchEqual
+	case KindFuzzy:
+		match = fuzzy.Match
+	}
+
+	result, ok := match(text, term.Pattern)
+	if term.Negated {
+		return Result{}, !ok
+	}
+
+	return Result{Score: result.Score, Positions: result.Positions}, ok
+}
+
+// anyWindow reports whether the pattern of the term matches any window or pane of the session, ignoring negation.
+func anyWindow(term Term, s *session.Session) bool {
+	for i := range s.Windows {
+		if term.Field == FieldWindow && matchesPattern(term, s.Windows[i].Name) {
+			return true
+		}
+
+		if term.Field == FieldCommand && anyPane(term, &s.Windows[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// anyPane reports whether the pattern of the term matches the command of any pane of the window, ignoring negation.
+func anyPane(term Term, w *session.Window) bool {
+	for _, pane := range w.Panes {
+		if matchesPattern(term, pane.CurrentCommand) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func anyText(term Term, texts []string) bool {
+	for _, text := range texts {
+		if matchesPattern(term, text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesPattern(term Term, text string) bool {
+	term.Negated = false
+	_, ok := matchTerm(term, text)
+
+	return ok
+}
+
+// Tags returns the tags of the session matched by `tag:` terms.
+func Tags(s *session.Session) []string {
+	tags := make([]string, 0)
+
+	if s.IsAttached {
+		tags = append(tags, "attached")
+	}
+
+	if s.IsHibernated {
+		tags = append(tags, "hibernated")
+	}
+
+	if s.GitStatus != nil {
+		tags = append(tags, "git")
+
+		if s.GitStatus.IsDirty {
+			tags = append(tags, "dirty")
+		}
+	}
+
+	if s.Source != "" {
+		tags = append(tags, s.Source)
+	}
+
+	return tags
+}
+
+func (r *Result) merge(other Result) {
+	r.Score += other.Score
+	r.Positions = mergePositions(r.Positions, other.Positions)
+}
+
+func (r *SessionResult) merge(other SessionResult) {
+	r.Result.merge(other.Result)
+	r.RefPositions = mergePositions(r.RefPositions, other.RefPositions)
+}
+
+// mergePositions returns the sorted union of the positions.
+func mergePositions(positions, other []int) []int {
+	if len(other) == 0 {
+		return positions
+	}
+
+	merged := make([]int, 0, len(positions)+len(other))
+	i, j := 0, 0
+
+	for i < len(positions) || j < len(other) {
+		switch {
+		case j == len(other) || (i < len(positions) && positions[i] < other[j]):
+			merged = append(merged, positions[i])
+			i++
+		case i == len(positions) || other[j] < positions[i]:
+			merged = append(merged, other[j])
+			j++
+		default:
+			merged = append(merged, positions[i])
+			i++
+			j++
+		}
+	}
+
+	return merged
+}
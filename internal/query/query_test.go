@@ -0,0 +1,156 @@
+package query_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/verte-zerg/gession/internal/query"
+	"github.com/verte-zerg/gession/internal/session"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected [][]query.Term
+	}{
+		{input: "  ", expected: nil},
+		{
+			input: "api  vim",
+			expected: [][]query.Term{
+				{{Field: query.FieldName, Kind: query.KindFuzzy, Pattern: "api"}},
+				{{Field: query.FieldCommand, Kind: query.KindFuzzy, Pattern: "vim"}},
+			},
+		},
+		{
+			input: "api edit vim extra",
+			expected: [][]query.Term{
+				{{Field: query.FieldName, Kind: query.KindFuzzy, Pattern: "api"}},
+				{{Field: query.FieldWindow, Kind: query.KindFuzzy, Pattern: "edit"}},
+				{{Field: query.FieldCommand, Kind: query.KindFuzzy, Pattern: "vim"}},
+			},
+		},
+		{
+			input: "api !test",
+			expected: [][]query.Term{
+				{{Field: query.FieldName, Kind: query.KindFuzzy, Pattern: "api"}},
+				{{Field: query.FieldName, Kind: query.KindExact, Pattern: "test", Negated: true}},
+			},
+		},
+		{
+			input: "dir:code win:^vim$ cmd:'npm tag:",
+			expected: [][]query.Term{
+				{{Field: query.FieldDirectory, Kind: query.KindFuzzy, Pattern: "code"}},
+				{{Field: query.FieldWindow, Kind: query.KindEqual, Pattern: "vim"}},
+				{{Field: query.FieldCommand, Kind: query.KindExact, Pattern: "npm"}},
+			},
+		},
+		{
+			input: "^web | api$|!dir:tmp",
+			expected: [][]query.Term{
+				{
+					{Field: query.FieldName, Kind: query.KindPrefix, Pattern: "web"},
+					{Field: query.FieldName, Kind: query.KindSuffix, Pattern: "api"},
+					{Field: query.FieldDirectory, Kind: query.KindExact, Pattern: "tmp", Negated: true},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			parsed := query.Parse(tt.input)
+			if !reflect.DeepEqual(parsed.Groups, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, parsed.Groups)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	sessions := []*session.Session{
+		{
+			Name:       "web-api",
+			Directory:  "/code/web",
+			IsAttached: true,
+			Windows: []session.Window{
+				{Name: "editor", Panes: []session.Pane{{CurrentCommand: "nvim"}}},
+				{Name: "server", Panes: []session.Pane{{CurrentCommand: "npm"}}},
+			},
+		},
+		{
+			Name:      "dotfiles",
+			Directory: "/home/user/dotfiles",
+			GitStatus: &session.GitStatus{Branch: "main", IsDirty: true},
+			Windows:   []session.Window{{Name: "shell", Panes: []session.Pane{{CurrentCommand: "bash"}}}},
+		},
+		{Name: "notes", Directory: "/tmp/notes", IsHibernated: true},
+	}
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{input: "", expected: []string{"web-api", "dotfiles", "notes"}},
+		{input: "api | notes", expected: []string{"web-api", "notes"}},
+		{input: "!web", expected: []string{"dotfiles", "notes"}},
+		{input: "main", expected: []string{"dotfiles"}},
+		{input: "dir:code", expected: []string{"web-api"}},
+		{input: "dir:!tmp", expected: []string{"web-api", "dotfiles"}},
+		{input: "win:serv", expected: []string{"web-api"}},
+		{input: "cmd:^bash$", expected: []string{"dotfiles"}},
+		{input: "!win:editor", expected: []string{"dotfiles", "notes"}},
+		{input: "tag:dirty | tag:hibernated", expected: []string{"dotfiles", "notes"}},
+		{input: "tag:attached win:editor", expected: []string{"web-api"}},
+		{input: "'ipa", expected: []string{}},
+		{input: "api editor", expected: []string{"web-api"}},
+		{input: "web shell", expected: []string{}},
+		{input: "f  bash", expected: []string{"dotfiles"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			parsed := query.Parse(tt.input)
+			matched := make([]string, 0)
+
+			for _, s := range sessions {
+				if _, ok := parsed.MatchSession(s); ok {
+					matched = append(matched, s.Name)
+				}
+			}
+
+			if !reflect.DeepEqual(matched, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, matched)
+			}
+		})
+	}
+}
+
+func TestMatchWindowAndPane(t *testing.T) {
+	s := &session.Session{
+		Name: "web-api",
+		Windows: []session.Window{
+			{Name: "editor", Panes: []session.Pane{{CurrentCommand: "nvim"}, {CurrentCommand: "bash"}}},
+			{Name: "server", Panes: []session.Pane{{CurrentCommand: "npm"}}},
+		},
+	}
+
+	parsed := query.Parse("api win:edit cmd:vim")
+
+	sessionResult, ok := parsed.MatchSession(s)
+	if !ok || !reflect.DeepEqual(sessionResult.Positions, []int{4, 5, 6}) {
+		t.Errorf("Expected the session to match at [4 5 6], got %v %v", ok, sessionResult.Positions)
+	}
+
+	windowResult, ok := parsed.MatchWindow(s, &s.Windows[0])
+	if !ok || !reflect.DeepEqual(windowResult.Positions, []int{0, 1, 2, 3}) {
+		t.Errorf("Expected the editor window to match at [0 1 2 3], got %v %v", ok, windowResult.Positions)
+	}
+
+	if _, ok := parsed.MatchWindow(s, &s.Windows[1]); ok {
+		t.Error("Expected the server window not to match")
+	}
+
+	if _, ok := parsed.MatchPane(s, &s.Windows[0], &s.Windows[0].Panes[1]); ok {
+		t.Error("Expected the bash pane not to match")
+	}
+}
@@ -8,19 +8,36 @@ import (
 	"strings"
 	"time"
 
+	"github.com/verte-zerg/gession/internal/sessiontemplate"
 	"github.com/verte-zerg/gession/pkg/logging"
 )
 
 var (
 	logger = logging.GetInstance().WithGroup("session")
+
+	// nameReplacer replaces characters tmux doesn't allow in session names.
+	nameReplacer = strings.NewReplacer(".", "_", ":", "_")
 )
 
+// SanitizeName replaces characters tmux doesn't allow in session names.
+func SanitizeName(name string) string {
+	return nameReplacer.Replace(name)
+}
+
 type Pane struct {
 	ID             string
 	CurrentCommand string
+	CurrentPath    string
+	PID            int
 	Index          int
 	IsActive       bool
 	Snapshot       *string
+
+	// Geometry of the pane inside its window, in cells
+	Width  int
+	Height int
+	Left   int
+	Top    int
 }
 
 type Window struct {
@@ -28,7 +45,11 @@ type Window struct {
 	Name     string
 	Index    int
 	IsActive bool
+	Layout   string
 	Panes    []Pane
+
+	// Directory is the path of a git worktree, worktrees are listed as windows of prime sessions.
+	Directory string
 }
 
 type Session struct {
@@ -36,8 +57,57 @@ type Session struct {
 	Name             string
 	IsAttached       bool
 	LastTimeAttached time.Time
+	Created          time.Time
+	LastActivity     time.Time
 	Windows          []Window
 	Directory        string
+
+	// LinkedSession is the live tmux session started in the directory of a prime session.
+	LinkedSession *Session
+
+	// GitStatus is the state of the git repository in the directory, nil if it's not a repository or unknown yet.
+	GitStatus *GitStatus
+
+	// Template is the layout a prime session is created from, it's set for sessions imported from other tools.
+	Template *sessiontemplate.Template
+	// Source is the tool an imported session is defined in.
+	Source string
+	// IsHibernated is set for sessions saved to disk and killed, they are woken from the Template.
+	IsHibernated bool
+}
+
+type GitStatus struct {
+	// Branch is the checked out branch, empty on detached HEAD.
+	Branch string
+	// Commit is the abbreviated hash of HEAD, empty before the first commit.
+	Commit     string
+	IsDetached bool
+	IsDirty    bool
+	// Ahead and Behind are numbers of commits compared to the upstream branch.
+	Ahead  int
+	Behind int
+	// Worktrees are linked worktrees of the repository, the main worktree isn't included.
+	Worktrees []GitWorktree
+}
+
+type GitWorktree struct {
+	Path       string
+	Branch     string
+	Commit     string
+	IsDetached bool
+}
+
+// Ref returns the branch name, or the commit for detached HEAD.
+func (g GitStatus) Ref() string {
+	if !g.IsDetached {
+		return g.Branch
+	}
+
+	if g.Commit == "" {
+		return "detached"
+	}
+
+	return "detached@" + g.Commit
 }
 
 func (s Session) GetPanesWithoutSnapshot() []Pane {
@@ -66,107 +136,180 @@ func (s *Session) SetSnapshot(paneID string, snapshot string) {
 	}
 }
 
+const (
+	// FieldSeparator separates pane fields in the list-panes output.
+	// tmux escapes control characters in session and window names (`\037`), so names can't clash with it.
+	// Paths are reported as they are, a path with the separator or a newline fails the parsing instead of shifting the fields.
+	FieldSeparator = "\x1f"
+)
+
+// paneFields are the tmux format variables requested for every pane, in the order they are parsed.
+var paneFields = []string{
+	"session_id",
+	"session_name",
+	"session_path",
+	"session_attached",
+	"session_last_attached",
+	"session_created",
+	"session_activity",
+	"window_id",
+	"window_name",
+	"window_index",
+	"window_active",
+	"window_layout",
+	"pane_id",
+	"pane_index",
+	"pane_active",
+	"pane_current_command",
+	"pane_current_path",
+	"pane_pid",
+	"pane_width",
+	"pane_height",
+	"pane_left",
+	"pane_top",
+}
+
+// PaneFormat returns the list-panes format string for the fields parsed by ParseSessions.
+func PaneFormat(separator string) string {
+	variables := make([]string, 0, len(paneFields))
+
+	for _, field := range paneFields {
+		variables = append(variables, "#{"+field+"}")
+	}
+
+	return strings.Join(variables, separator)
+}
+
 type tmuxPaneResponse struct {
-	sessionName        string
-	windowName         string
-	paneCurrentCommand string
-	windowIndex        int
-	paneIndex          int
-	sessionAttached    bool
-	windowActive       bool
-	paneActive         bool
-	lastAttached       time.Time
-	sessionID          string
-	windowID           string
-	paneID             string
+	sessionName         string
+	sessionPath         string
+	windowName          string
+	windowLayout        string
+	paneCurrentCommand  string
+	paneCurrentPath     string
+	windowIndex         int
+	paneIndex           int
+	panePID             int
+	paneWidth           int
+	paneHeight          int
+	paneLeft            int
+	paneTop             int
+	sessionAttached     bool
+	windowActive        bool
+	paneActive          bool
+	lastAttached        time.Time
+	sessionCreated      time.Time
+	sessionLastActivity time.Time
+	sessionID           string
+	windowID            string
+	paneID              string
 }
 
-func parseResponse(response string) (*tmuxPaneResponse, error) {
-	parts := strings.Split(response, "|")
-	sessionName := parts[0]
-	windowName := parts[1]
-	paneCurrentCommand := parts[2]
+type paneFieldsParser struct {
+	fields map[string]string
+	err    error
+}
+
+// int parses the integer field, the first error is kept and reported by the parser.
+func (p *paneFieldsParser) int(field string) int {
+	value, err := strconv.Atoi(p.fields[field])
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("could not convert %s to int: %w", field, err)
+	}
 
-	indices := strings.Split(parts[3], ".")
-	windowIndex, err := strconv.Atoi(indices[0])
+	return value
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("could not convert window index to int: %w", err)
+// time parses the unix timestamp field, empty field means the time is unknown.
+func (p *paneFieldsParser) time(field string) time.Time {
+	if p.fields[field] == "" {
+		return time.Time{}
 	}
 
-	paneIndex, err := strconv.Atoi(indices[1])
-	if err != nil {
-		return nil, fmt.Errorf("could not convert pane index to int: %w", err)
+	ts, err := strconv.ParseInt(p.fields[field], 10, 64)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("could not convert %s timestamp to int: %w", field, err)
 	}
 
-	attachedParts := strings.Split(parts[4], ".")
-	sessionAttached := attachedParts[0] != "0"
-	windowActive := attachedParts[1] == "1"
-	paneActive := attachedParts[2] == "1"
+	return time.Unix(ts, 0)
+}
 
-	lastAttached := time.Time{}
+func parseResponse(response string) (*tmuxPaneResponse, error) {
+	parts := strings.Split(response, FieldSeparator)
+	if len(parts) != len(paneFields) {
+		return nil, fmt.Errorf("expected %d fields, got %d in %q", len(paneFields), len(parts), response)
+	}
 
-	if len(parts[5]) > 0 {
-		ts, err := strconv.ParseInt(parts[5], 10, 64)
-		if err != nil {
-			return nil, fmt.Errorf("could not convert last attached timestamp to int: %w", err)
-		}
+	fields := make(map[string]string, len(paneFields))
+	for i, field := range paneFields {
+		fields[field] = parts[i]
+	}
+
+	parser := paneFieldsParser{fields: fields}
+
+	paneResponse := &tmuxPaneResponse{
+		sessionName:         fields["session_name"],
+		sessionPath:         fields["session_path"],
+		windowName:          fields["window_name"],
+		windowLayout:        fields["window_layout"],
+		paneCurrentCommand:  fields["pane_current_command"],
+		paneCurrentPath:     fields["pane_current_path"],
+		windowIndex:         parser.int("window_index"),
+		paneIndex:           parser.int("pane_index"),
+		panePID:             parser.int("pane_pid"),
+		paneWidth:           parser.int("pane_width"),
+		paneHeight:          parser.int("pane_height"),
+		paneLeft:            parser.int("pane_left"),
+		paneTop:             parser.int("pane_top"),
+		sessionAttached:     fields["session_attached"] != "0",
+		windowActive:        fields["window_active"] == "1",
+		paneActive:          fields["pane_active"] == "1",
+		lastAttached:        parser.time("session_last_attached"),
+		sessionCreated:      parser.time("session_created"),
+		sessionLastActivity: parser.time("session_activity"),
+		paneID:              fields["pane_id"],
+		sessionID:           fields["session_id"],
+		windowID:            fields["window_id"],
+	}
 
-		lastAttached = time.Unix(ts, 0)
+	if parser.err != nil {
+		return nil, parser.err
 	}
 
-	ids := parts[6]
-	idParts := strings.Split(ids, ".")
-	sessionID := idParts[0]
-	windowID := idParts[1]
-	paneID := idParts[2]
-
-	logger.Info("Parsed response", slog.String("sessionName", sessionName), slog.String("windowName", windowName), slog.String("paneID", paneID))
-
-	return &tmuxPaneResponse{
-		sessionName:        sessionName,
-		windowName:         windowName,
-		paneCurrentCommand: paneCurrentCommand,
-		windowIndex:        windowIndex,
-		paneIndex:          paneIndex,
-		sessionAttached:    sessionAttached,
-		windowActive:       windowActive,
-		paneActive:         paneActive,
-		lastAttached:       lastAttached,
-		paneID:             paneID,
-		sessionID:          sessionID,
-		windowID:           windowID,
-	}, nil
+	logger.Info("Parsed response", slog.String("sessionName", paneResponse.sessionName), slog.String("windowName", paneResponse.windowName), slog.String("paneID", paneResponse.paneID))
+
+	return paneResponse, nil
 }
 
+// ParseSessions builds the session tree from the list-panes output, one pane per line.
 func ParseSessions(response string) ([]*Session, error) {
-	panes := strings.Split(response, "\n")
-
 	rawSessions := make(map[string]map[int]map[int]*tmuxPaneResponse)
 
-	for _, line := range panes {
+	for lineIdx, line := range strings.Split(response, "\n") {
+		if line == "" {
+			continue
+		}
+
 		response, err := parseResponse(line)
 		if err != nil {
-			return nil, fmt.Errorf("could not parse response: %w", err)
+			return nil, fmt.Errorf("could not parse pane on line %d: %w", lineIdx+1, err)
 		}
 
-		if _, ok := rawSessions[response.sessionName]; !ok {
-			rawSessions[response.sessionName] = make(map[int]map[int]*tmuxPaneResponse)
+		if _, ok := rawSessions[response.sessionID]; !ok {
+			rawSessions[response.sessionID] = make(map[int]map[int]*tmuxPaneResponse)
 		}
 
-		if _, ok := rawSessions[response.sessionName][response.windowIndex]; !ok {
-			rawSessions[response.sessionName][response.windowIndex] = make(map[int]*tmuxPaneResponse)
+		if _, ok := rawSessions[response.sessionID][response.windowIndex]; !ok {
+			rawSessions[response.sessionID][response.windowIndex] = make(map[int]*tmuxPaneResponse)
 		}
 
-		rawSessions[response.sessionName][response.windowIndex][response.paneIndex] = response
+		rawSessions[response.sessionID][response.windowIndex][response.paneIndex] = response
 	}
 
 	sessionList := make([]*Session, 0, len(rawSessions))
 
-	for sessionName, windows := range rawSessions {
-		session := &Session{
-			Name: sessionName,
-		}
+	for _, windows := range rawSessions {
+		session := &Session{}
 
 		for windowIndex, panes := range windows {
 			window := Window{
@@ -179,17 +322,28 @@ func ParseSessions(response string) ([]*Session, error) {
 					Index:          paneIndex,
 					IsActive:       response.paneActive,
 					CurrentCommand: response.paneCurrentCommand,
+					CurrentPath:    response.paneCurrentPath,
+					PID:            response.panePID,
 					ID:             response.paneID,
 					Snapshot:       &emptySnapshot,
+					Width:          response.paneWidth,
+					Height:         response.paneHeight,
+					Left:           response.paneLeft,
+					Top:            response.paneTop,
 				}
 
 				session.ID = response.sessionID
+				session.Name = response.sessionName
+				session.Directory = response.sessionPath
 				session.IsAttached = response.sessionAttached
 				session.LastTimeAttached = response.lastAttached
+				session.Created = response.sessionCreated
+				session.LastActivity = response.sessionLastActivity
 				window.ID = response.windowID
 				window.Name = response.windowName
 				window.Index = response.windowIndex
 				window.IsActive = response.windowActive
+				window.Layout = response.windowLayout
 
 				window.Panes = append(window.Panes, pane)
 			}
@@ -209,6 +363,10 @@ func ParseSessions(response string) ([]*Session, error) {
 	}
 
 	sort.Slice(sessionList, func(i, j int) bool {
+		if sessionList[i].LastTimeAttached.Equal(sessionList[j].LastTimeAttached) {
+			return sessionList[i].ID < sessionList[j].ID
+		}
+
 		return sessionList[i].LastTimeAttached.After(sessionList[j].LastTimeAttached)
 	})
 
@@ -0,0 +1,236 @@
+package session_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/verte-zerg/gession/internal/session"
+)
+
+// paneFields is the order of fields in the list-panes format.
+var paneFields = []string{
+	"session_id", "session_name", "session_path", "session_attached", "session_last_attached", "session_created", "session_activity",
+	"window_id", "window_name", "window_index", "window_active", "window_layout",
+	"pane_id", "pane_index", "pane_active", "pane_current_command", "pane_current_path", "pane_pid",
+	"pane_width", "pane_height", "pane_left", "pane_top",
+}
+
+var defaultPaneValues = map[string]string{
+	"session_id":            "$1",
+	"session_name":          "main",
+	"session_path":          "/home/user",
+	"session_attached":      "0",
+	"session_last_attached": "1700000000",
+	"session_created":       "1600000000",
+	"session_activity":      "1700000100",
+	"window_id":             "@1",
+	"window_name":           "editor",
+	"window_index":          "0",
+	"window_active":         "1",
+	"window_layout":         "b25d,80x24,0,0,0",
+	"pane_id":               "%1",
+	"pane_index":            "0",
+	"pane_active":           "1",
+	"pane_current_command":  "zsh",
+	"pane_current_path":     "/home/user",
+	"pane_pid":              "4242",
+	"pane_width":            "80",
+	"pane_height":           "24",
+	"pane_left":             "0",
+	"pane_top":              "0",
+}
+
+func paneLine(values map[string]string) string {
+	parts := make([]string, 0, len(paneFields))
+
+	for _, field := range paneFields {
+		value, ok := values[field]
+		if !ok {
+			value = defaultPaneValues[field]
+		}
+
+		parts = append(parts, value)
+	}
+
+	return strings.Join(parts, session.FieldSeparator)
+}
+
+func TestParseSessionsHostileNames(t *testing.T) {
+	tests := []struct {
+		name        string
+		sessionName string
+		windowName  string
+		command     string
+	}{
+		{
+			name:        "Plain names",
+			sessionName: "api",
+			windowName:  "editor",
+			command:     "nvim",
+		},
+		{
+			name:        "Pipes in names",
+			sessionName: "api|db",
+			windowName:  "logs|tail",
+			command:     "tail",
+		},
+		{
+			name:        "Dots in names",
+			sessionName: "example.com",
+			windowName:  "v1.2.3",
+			command:     "node.js",
+		},
+		{
+			name:        "Spaces and quotes",
+			sessionName: `my "quoted" session`,
+			windowName:  "it's a window",
+			command:     "bash",
+		},
+		{
+			name:        "Format-like sequences",
+			sessionName: "#{session_name}",
+			windowName:  "%begin 1 2 1",
+			command:     "$SHELL",
+		},
+		{
+			name:        "Unicode",
+			sessionName: "проект🍺",
+			windowName:  "窗口",
+			command:     "zsh",
+		},
+		{
+			name:        "Control characters escaped by tmux",
+			sessionName: `api\037db`,
+			windowName:  `logs\012tail`,
+			command:     "zsh",
+		},
+		{
+			name:        "Empty window name",
+			sessionName: "empty",
+			windowName:  "",
+			command:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := paneLine(map[string]string{
+				"session_name":         tt.sessionName,
+				"window_name":          tt.windowName,
+				"pane_current_command": tt.command,
+				"pane_current_path":    "/tmp/" + tt.sessionName,
+			})
+
+			sessions, err := session.ParseSessions(response)
+			if err != nil {
+				t.Fatalf("Expected no error, got `%v`", err)
+			}
+
+			if len(sessions) != 1 || len(sessions[0].Windows) != 1 || len(sessions[0].Windows[0].Panes) != 1 {
+				t.Fatalf("Expected exactly one session with one window and one pane, got `%+v`", sessions)
+			}
+
+			s := sessions[0]
+			if s.Name != tt.sessionName {
+				t.Errorf("Expected session name to be `%s`, got `%s`", tt.sessionName, s.Name)
+			}
+
+			if s.Windows[0].Name != tt.windowName {
+				t.Errorf("Expected window name to be `%s`, got `%s`", tt.windowName, s.Windows[0].Name)
+			}
+
+			if s.Windows[0].Panes[0].CurrentCommand != tt.command {
+				t.Errorf("Expected pane command to be `%s`, got `%s`", tt.command, s.Windows[0].Panes[0].CurrentCommand)
+			}
+
+			if s.Windows[0].Panes[0].CurrentPath != "/tmp/"+tt.sessionName {
+				t.Errorf("Expected pane path to be `/tmp/%s`, got `%s`", tt.sessionName, s.Windows[0].Panes[0].CurrentPath)
+			}
+		})
+	}
+}
+
+func TestParseSessionsTree(t *testing.T) {
+	response := strings.Join([]string{
+		paneLine(map[string]string{"session_last_attached": "100", "window_id": "@2", "window_index": "1", "pane_id": "%3"}),
+		paneLine(map[string]string{"session_last_attached": "100", "pane_id": "%2", "pane_index": "1", "pane_left": "41", "pane_width": "39"}),
+		paneLine(map[string]string{"session_last_attached": "100", "pane_id": "%1", "pane_width": "40"}),
+		paneLine(map[string]string{"session_id": "$2", "session_name": "b", "session_path": "/srv", "session_last_attached": "200", "window_id": "@3", "pane_id": "%4"}),
+	}, "\n") + "\n"
+
+	sessions, err := session.ParseSessions(response)
+	if err != nil {
+		t.Fatalf("Expected no error, got `%v`", err)
+	}
+
+	if len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(sessions))
+	}
+
+	a := sessions[0]
+	if a.ID != "$1" || len(a.Windows) != 2 {
+		t.Fatalf("Expected session `$1` with 2 windows, got `%s` with %d", a.ID, len(a.Windows))
+	}
+
+	if a.Windows[0].ID != "@1" || a.Windows[0].Panes[0].ID != "%1" || a.Windows[0].Panes[1].ID != "%2" {
+		t.Errorf("Expected windows and panes to be sorted by index, got `%+v`", a.Windows)
+	}
+
+	if a.Directory != "/home/user" || a.Created.Unix() != 1600000000 || a.LastActivity.Unix() != 1700000100 {
+		t.Errorf("Expected session path and timestamps to be parsed, got `%+v`", a)
+	}
+
+	pane := a.Windows[0].Panes[1]
+	if pane.Left != 41 || pane.Top != 0 || pane.Width != 39 || pane.Height != 24 || pane.PID != 4242 {
+		t.Errorf("Expected pane geometry to be parsed, got `%+v`", pane)
+	}
+
+	if a.Windows[0].Layout != "b25d,80x24,0,0,0" {
+		t.Errorf("Expected window layout to be parsed, got `%s`", a.Windows[0].Layout)
+	}
+}
+
+func TestParseSessionsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{
+			name:     "Newline in window name splits the line",
+			response: paneLine(map[string]string{"window_name": "broken\nname"}),
+		},
+		{
+			name:     "Separator in pane path",
+			response: paneLine(map[string]string{"pane_current_path": "/tmp/a" + session.FieldSeparator + "b"}),
+		},
+		{
+			name:     "Newline in session path splits the line",
+			response: paneLine(map[string]string{"session_path": "/tmp/a\nb"}),
+		},
+		{
+			name:     "Legacy pipe separated line",
+			response: "a|w|zsh|0.0|0.1.1||$1.@1.%1",
+		},
+		{
+			name:     "Not a number window index",
+			response: paneLine(map[string]string{"window_index": "x"}),
+		},
+		{
+			name:     "Not a number pane width",
+			response: paneLine(map[string]string{"pane_width": "wide"}),
+		},
+		{
+			name:     "Not a number last attached",
+			response: paneLine(map[string]string{"session_last_attached": "yesterday"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := session.ParseSessions(tt.response)
+			if err == nil {
+				t.Errorf("Expected an error for response `%q`", tt.response)
+			}
+		})
+	}
+}
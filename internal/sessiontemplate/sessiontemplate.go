@@ -0,0 +1,185 @@
+package sessiontemplate
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ProjectFileName is the template looked up in the session directory.
+	ProjectFileName = ".gession.yaml"
+	// DefaultName is the global template used when there is no project or session template.
+	DefaultName = "default"
+
+	SplitHorizontal = "horizontal"
+	SplitVertical   = "vertical"
+)
+
+// Template describes the windows and panes of a session.
+type Template struct {
+	Environment map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	Windows     []Window          `json:"windows" yaml:"windows"`
+}
+
+type Window struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Directory is relative to the session directory if it isn't absolute.
+	Directory string `json:"dir,omitempty" yaml:"dir,omitempty"`
+	// Layout is a tmux layout name (tiled, main-vertical...) or a layout string, applied after panes are created.
+	Layout string `json:"layout,omitempty" yaml:"layout,omitempty"`
+	Focus  bool   `json:"focus,omitempty" yaml:"focus,omitempty"`
+	Panes  []Pane `json:"panes,omitempty" yaml:"panes,omitempty"`
+}
+
+type Pane struct {
+	// Command is typed into the pane shell, so the pane stays open after the command exits.
+	Command string `json:"command,omitempty" yaml:"command,omitempty"`
+	// Directory is relative to the window directory if it isn't absolute.
+	Directory string `json:"dir,omitempty" yaml:"dir,omitempty"`
+	// Split is how the pane is split from the previous one: horizontal (side by side) or vertical (stacked).
+	Split string `json:"split,omitempty" yaml:"split,omitempty"`
+	// Size is the size of the new pane in lines/columns or percents, e.g. `30%`.
+	Size  string `json:"size,omitempty" yaml:"size,omitempty"`
+	Focus bool   `json:"focus,omitempty" yaml:"focus,omitempty"`
+	// Scrollback is a file printed in the pane before its shell starts, the file is removed once it's printed.
+	Scrollback string `json:"scrollback,omitempty" yaml:"scrollback,omitempty"`
+}
+
+// UnmarshalYAML allows a pane to be written as its command only.
+func (p *Pane) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&p.Command) //nolint:wrapcheck
+	}
+
+	type plainPane Pane
+
+	return node.Decode((*plainPane)(p)) //nolint:wrapcheck
+}
+
+// Parse parses and validates a template.
+func Parse(data []byte) (*Template, error) {
+	template := &Template{}
+
+	err := yaml.Unmarshal(data, template)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse template: %w", err)
+	}
+
+	err = template.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// Validate checks that the template has windows and its splits are known.
+func (t *Template) Validate() error {
+	if len(t.Windows) == 0 {
+		return errors.New("template has no windows")
+	}
+
+	for i, window := range t.Windows {
+		for j, pane := range window.Panes {
+			if pane.Split != "" && pane.Split != SplitHorizontal && pane.Split != SplitVertical {
+				return fmt.Errorf("window %d, pane %d: unknown split %q", i, j, pane.Split)
+			}
+		}
+	}
+
+	return nil
+}
+
+// FilterCommands drops pane commands that aren't allowed and returns them, the panes are started with a shell only.
+func (t *Template) FilterCommands(isAllowed func(command string) bool) []string {
+	dropped := make([]string, 0)
+
+	for i := range t.Windows {
+		panes := t.Windows[i].Panes
+
+		for j := range panes {
+			if panes[j].Command != "" && !isAllowed(panes[j].Command) {
+				dropped = append(dropped, panes[j].Command)
+				panes[j].Command = ""
+			}
+		}
+	}
+
+	return dropped
+}
+
+// ReadFile reads the template from the file.
+func ReadFile(templatePath string) (*Template, error) {
+	data, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read template: %w", err)
+	}
+
+	return parseFile(templatePath, data)
+}
+
+func parseFile(templatePath string, data []byte) (*Template, error) {
+	template, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", templatePath, err)
+	}
+
+	return template, nil
+}
+
+// Find returns the template for a session: `.gession.yaml` in the directory,
+// then `<name>.yaml` and `default.yaml` in the templates directory.
+// It returns nil if there is no template, and an UntrustedError if the project template isn't in the trusted list.
+func Find(directory, name, templatesDir string, trusted *Trusted) (*Template, error) {
+	projectFile, err := filepath.Abs(filepath.Join(directory, ProjectFileName))
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve project template: %w", err)
+	}
+
+	candidates := []string{projectFile}
+
+	if templatesDir != "" {
+		candidates = append(candidates,
+			filepath.Join(templatesDir, name+".yaml"),
+			filepath.Join(templatesDir, DefaultName+".yaml"),
+		)
+	}
+
+	for _, candidate := range candidates {
+		data, err := os.ReadFile(candidate)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("could not read template: %w", err)
+		}
+
+		// The hash is of the data that is parsed, so a template changed after it's checked isn't run
+		if candidate == projectFile && !trusted.IsTrusted(candidate, hashOf(data)) {
+			return nil, &UntrustedError{Path: candidate, Hash: hashOf(data)}
+		}
+
+		return parseFile(candidate, data)
+	}
+
+	return nil, nil //nolint:nilnil
+}
+
+// ResolveDirectory returns the directory relative to the base, an empty directory is the base itself.
+func ResolveDirectory(base, directory string) string {
+	if directory == "" {
+		return base
+	}
+
+	if filepath.IsAbs(directory) {
+		return directory
+	}
+
+	return filepath.Join(base, directory)
+}
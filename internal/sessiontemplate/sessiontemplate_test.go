@@ -0,0 +1,211 @@
+package sessiontemplate_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/verte-zerg/gession/internal/sessiontemplate"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected sessiontemplate.Template
+	}{
+		{
+			name: "Panes as commands",
+			data: "windows:\n" +
+				"  - name: editor\n" +
+				"    panes: [nvim, git status]\n",
+			expected: sessiontemplate.Template{
+				Windows: []sessiontemplate.Window{
+					{Name: "editor", Panes: []sessiontemplate.Pane{{Command: "nvim"}, {Command: "git status"}}},
+				},
+			},
+		},
+		{
+			name: "Full template",
+			data: "env:\n" +
+				"  PORT: \"8080\"\n" +
+				"windows:\n" +
+				"  - name: editor\n" +
+				"  - name: server\n" +
+				"    dir: web\n" +
+				"    layout: main-vertical\n" +
+				"    focus: true\n" +
+				"    panes:\n" +
+				"      - command: npm run dev\n" +
+				"      - command: npm test -- --watch\n" +
+				"        split: horizontal\n" +
+				"        size: 30%\n" +
+				"        focus: true\n",
+			expected: sessiontemplate.Template{
+				Environment: map[string]string{"PORT": "8080"},
+				Windows: []sessiontemplate.Window{
+					{Name: "editor"},
+					{
+						Name:      "server",
+						Directory: "web",
+						Layout:    "main-vertical",
+						Focus:     true,
+						Panes: []sessiontemplate.Pane{
+							{Command: "npm run dev"},
+							{Command: "npm test -- --watch", Split: "horizontal", Size: "30%", Focus: true},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := sessiontemplate.Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(*template, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, *template)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "No windows", data: "env:\n  PORT: \"8080\"\n"},
+		{name: "Unknown split", data: "windows:\n  - panes:\n      - split: diagonal\n"},
+		{name: "Invalid yaml", data: "windows: [\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := sessiontemplate.Parse([]byte(tt.data)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	projectDir := t.TempDir()
+	templatesDir := t.TempDir()
+
+	writeTemplate(t, filepath.Join(templatesDir, "default.yaml"), "default")
+	writeTemplate(t, filepath.Join(templatesDir, "api.yaml"), "api")
+
+	trusted, err := sessiontemplate.LoadTrusted(filepath.Join(t.TempDir(), "trusted.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		setup    func()
+		session  string
+		expected string
+	}{
+		{name: "Default template", session: "web", expected: "default"},
+		{name: "Session template", session: "api", expected: "api"},
+		{
+			name: "Project template",
+			setup: func() {
+				writeTemplate(t, filepath.Join(projectDir, sessiontemplate.ProjectFileName), "project")
+				trustProjectTemplate(t, projectDir, trusted)
+			},
+			session:  "api",
+			expected: "project",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			template, err := sessiontemplate.Find(projectDir, tt.session, templatesDir, trusted)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if template == nil || template.Windows[0].Name != tt.expected {
+				t.Errorf("Expected template %q, got %+v", tt.expected, template)
+			}
+		})
+	}
+
+	template, err := sessiontemplate.Find(t.TempDir(), "api", "", trusted)
+	if err != nil || template != nil {
+		t.Errorf("Expected no template, got %+v, %v", template, err)
+	}
+}
+
+func TestFindUntrusted(t *testing.T) {
+	projectDir := t.TempDir()
+	trustedPath := filepath.Join(t.TempDir(), "gession", "trusted.json")
+	projectFile := filepath.Join(projectDir, sessiontemplate.ProjectFileName)
+
+	writeTemplate(t, projectFile, "project")
+
+	trusted, err := sessiontemplate.LoadTrusted(trustedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	untrusted := &sessiontemplate.UntrustedError{}
+	if _, err := sessiontemplate.Find(projectDir, "api", "", trusted); !errors.As(err, &untrusted) || untrusted.Path != projectFile {
+		t.Fatalf("Expected %s to be untrusted, got %v", projectFile, err)
+	}
+
+	if err := trusted.Trust(untrusted.Path, untrusted.Hash); err != nil {
+		t.Fatal(err)
+	}
+
+	// The list is saved, so the template stays trusted
+	trusted, err = sessiontemplate.LoadTrusted(trustedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if template, err := sessiontemplate.Find(projectDir, "api", "", trusted); err != nil || template == nil {
+		t.Fatalf("Expected the trusted template, got %+v, %v", template, err)
+	}
+
+	// A changed template has to be trusted again
+	writeTemplate(t, projectFile, "changed")
+
+	if _, err := sessiontemplate.Find(projectDir, "api", "", trusted); !errors.As(err, &untrusted) {
+		t.Errorf("Expected the changed template to be untrusted, got %v", err)
+	}
+}
+
+func trustProjectTemplate(t *testing.T, projectDir string, trusted *sessiontemplate.Trusted) {
+	t.Helper()
+
+	untrusted := &sessiontemplate.UntrustedError{}
+	if _, err := sessiontemplate.Find(projectDir, "", "", trusted); !errors.As(err, &untrusted) {
+		t.Fatalf("Expected the project template to be untrusted, got %v", err)
+	}
+
+	if err := trusted.Trust(untrusted.Path, untrusted.Hash); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTemplate(t *testing.T, templatePath, windowName string) {
+	t.Helper()
+
+	err := os.WriteFile(templatePath, []byte("windows:\n  - name: "+windowName+"\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,103 @@
+package sessiontemplate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	dirMode  = 0o755
+	fileMode = 0o600
+)
+
+// Trusted are the project templates the user allowed to run, by the hash of their content.
+// A project template comes with the code, so a cloned repository can't type commands into panes until it's trusted,
+// and a changed template has to be trusted again.
+type Trusted struct {
+	path   string
+	hashes map[string]string
+}
+
+// UntrustedError is returned for a project template that isn't trusted or was changed since it was trusted.
+type UntrustedError struct {
+	Path string
+	Hash string
+}
+
+func (e *UntrustedError) Error() string {
+	return e.Path + " is not trusted"
+}
+
+// LoadTrusted reads the trusted templates from the file, a missing file trusts nothing.
+func LoadTrusted(trustedPath string) (*Trusted, error) {
+	trusted := &Trusted{path: trustedPath, hashes: make(map[string]string)}
+
+	data, err := os.ReadFile(trustedPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return trusted, nil
+	}
+
+	if err != nil {
+		return trusted, fmt.Errorf("could not read trusted templates: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &trusted.hashes); err != nil {
+		return trusted, fmt.Errorf("could not parse trusted templates: %w", err)
+	}
+
+	return trusted, nil
+}
+
+// IsTrusted reports whether the template with the content hash is trusted, nothing is trusted by a nil list.
+func (t *Trusted) IsTrusted(templatePath, hash string) bool {
+	return t != nil && t.hashes[templatePath] == hash
+}
+
+// Trust adds the template to the list and replaces the file with it atomically.
+func (t *Trusted) Trust(templatePath, hash string) error {
+	t.hashes[templatePath] = hash
+
+	data, err := json.MarshalIndent(t.hashes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode trusted templates: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(t.path), dirMode)
+	if err != nil {
+		return fmt.Errorf("could not create trusted templates directory: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(t.path), filepath.Base(t.path)+".*")
+	if err != nil {
+		return fmt.Errorf("could not create trusted templates file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not write trusted templates file: %w", err)
+	}
+
+	err = os.Rename(tmpFile.Name(), t.path)
+	if err != nil {
+		return fmt.Errorf("could not replace trusted templates file: %w", err)
+	}
+
+	return nil
+}
+
+func hashOf(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
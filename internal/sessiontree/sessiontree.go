@@ -1,8 +1,10 @@
 package sessiontree
 
 import (
+	"sort"
 	"strings"
 
+	"github.com/verte-zerg/gession/internal/query"
 	"github.com/verte-zerg/gession/internal/session"
 	"github.com/verte-zerg/gession/pkg/assert"
 	"github.com/verte-zerg/gession/pkg/fuzzy"
@@ -12,8 +14,6 @@ const (
 	BOLD  = "\033[1m"
 	GREEN = "\033[32m"
 	RESET = "\033[0m"
-
-	PARTS = 3
 )
 
 type VisualizeTree struct {
@@ -70,42 +70,57 @@ type FilteredSession struct {
 
 	IsUnwrapped      bool
 	FilteredChildren []*FilteredWindow
-	query            string
+	positions        []int
+	refPositions     []int
+	// score is the match score of the session with its best window, sessions are sorted by it.
+	score int
 }
 
 func (s FilteredSession) GetString(bold bool) string {
-	return getRepresentation(s.Name, s.query, bold)
+	return getRepresentation(s.Name, s.positions, bold)
+}
+
+// GetGitRefString returns the git ref of the session, highlighted if the session is found by it rather than by name.
+func (s FilteredSession) GetGitRefString() string {
+	if s.GitStatus == nil {
+		return ""
+	}
+
+	return getRepresentation(s.GitStatus.Ref(), s.refPositions, false)
 }
 
 type FilteredWindow struct {
 	*session.Window
 
 	FilteredChildren []*FilteredPane
-	query            string
+	positions        []int
+	score            int
 }
 
 func (w FilteredWindow) GetString(bold bool) string {
-	return getRepresentation(w.Name, w.query, bold)
+	return getRepresentation(w.Name, w.positions, bold)
 }
 
 type FilteredPane struct {
 	*session.Pane
 
-	query string
+	positions []int
+	score     int
 }
 
 func (p FilteredPane) GetString(bold bool) string {
-	return getRepresentation(p.CurrentCommand, p.query, bold)
+	return getRepresentation(p.CurrentCommand, p.positions, bold)
 }
 
-func getRepresentation(name, query string, bold bool) string {
+// getRepresentation highlights the matched chars of the name.
+func getRepresentation(name string, positions []int, bold bool) string {
 	boldMarker := ""
 	if bold {
 		boldMarker = BOLD
 	}
 
 	builder := strings.Builder{}
-	representation, _ := fuzzy.SearchColorized(name, query)
+	representation := fuzzy.Colorize(name, positions)
 
 	for _, c := range representation {
 		if c.Highlighted {
@@ -118,66 +133,73 @@ func getRepresentation(name, query string, bold bool) string {
 	return builder.String()
 }
 
+// SearchEntities filters the sessions, windows and panes by the query and sorts them by score.
+// See query.Parse for the syntax.
+//
 //nolint:cyclop
 func (vt *VisualizeTree) SearchEntities(
-	query string,
+	input string,
 	sessions []*session.Session,
 	selectedIdx int,
 	unwrappedSession map[string]interface{},
 ) []FilteredSession {
 	vt.sessionsCount = len(sessions)
-	query = strings.TrimSpace(query)
 	vt.selectedIdx = selectedIdx
 
-	queryParts := strings.Split(query, " ")
-	if len(queryParts) > PARTS {
-		queryParts = queryParts[:PARTS]
-	}
-
-	for len(queryParts) < PARTS {
-		queryParts = append(queryParts, "")
-	}
+	parsedQuery := query.Parse(input)
 
 	tree := make([]FilteredSession, 0)
 	visibleRows := 0
 
 	for _, session := range sessions {
-		if !fuzzy.Search(session.Name, queryParts[0]) {
+		sessionResult, ok := parsedQuery.MatchSession(session)
+		if !ok {
 			continue
 		}
 
 		isUnwrapped := unwrappedSession[session.ID] != nil
 
 		filteredSession := FilteredSession{
-			Session:     session,
-			IsUnwrapped: isUnwrapped,
-			query:       queryParts[0],
+			Session:      session,
+			IsUnwrapped:  isUnwrapped,
+			positions:    sessionResult.Positions,
+			refPositions: sessionResult.RefPositions,
 		}
 
 		for _, window := range session.Windows {
-			if !fuzzy.Search(window.Name, queryParts[1]) {
+			windowResult, ok := parsedQuery.MatchWindow(session, &window)
+			if !ok {
 				continue
 			}
 
 			filteredWindow := FilteredWindow{
-				Window: &window,
-				query:  queryParts[1],
+				Window:    &window,
+				positions: windowResult.Positions,
 			}
 
 			for _, pane := range window.Panes {
-				if !fuzzy.Search(pane.CurrentCommand, queryParts[2]) {
+				paneResult, ok := parsedQuery.MatchPane(session, &window, &pane)
+				if !ok {
 					continue
 				}
 
 				filteredPane := FilteredPane{
-					Pane:  &pane,
-					query: queryParts[2],
+					Pane:      &pane,
+					positions: paneResult.Positions,
+					score:     paneResult.Score,
 				}
 
 				filteredWindow.FilteredChildren = append(filteredWindow.FilteredChildren, &filteredPane)
 			}
 
 			if len(filteredWindow.FilteredChildren) != 0 || vt.showEmptyEntities {
+				sortByScore(filteredWindow.FilteredChildren, func(pane *FilteredPane) int { return pane.score })
+
+				filteredWindow.score = windowResult.Score
+				if len(filteredWindow.FilteredChildren) != 0 {
+					filteredWindow.score += filteredWindow.FilteredChildren[0].score
+				}
+
 				filteredSession.FilteredChildren = append(filteredSession.FilteredChildren, &filteredWindow)
 
 				if isUnwrapped {
@@ -186,12 +208,25 @@ func (vt *VisualizeTree) SearchEntities(
 			}
 		}
 
-		if len(filteredSession.FilteredChildren) != 0 || vt.showEmptyEntities {
+		sortByScore(filteredSession.FilteredChildren, func(window *FilteredWindow) int { return window.score })
+
+		filteredSession.score = sessionResult.Score
+		if len(filteredSession.FilteredChildren) != 0 {
+			filteredSession.score += filteredSession.FilteredChildren[0].score
+		}
+
+		// Sessions without windows, like folders in combined mode, are filtered by the session terms only
+		isEmptyShown := vt.showEmptyEntities || len(session.Windows) == 0
+
+		if len(filteredSession.FilteredChildren) != 0 || isEmptyShown {
 			tree = append(tree, filteredSession)
 			visibleRows++
 		}
 	}
 
+	// Entities with equal scores, e.g. all of them with an empty query, keep the original order, which is by frecency
+	sortByScore(tree, func(session FilteredSession) int { return session.score })
+
 	vt.visibleRows = visibleRows
 	vt.tree = tree
 	vt.markSelectedEntities()
@@ -199,6 +234,13 @@ func (vt *VisualizeTree) SearchEntities(
 	return tree
 }
 
+// sortByScore sorts the entities from the best match, keeping the order of equal ones.
+func sortByScore[T any](entities []T, score func(T) int) {
+	sort.SliceStable(entities, func(i, j int) bool {
+		return score(entities[i]) > score(entities[j])
+	})
+}
+
 func (vt *VisualizeTree) markSelectedEntities() {
 	vt.selectedSession = nil
 	vt.selectedWindow = nil
@@ -0,0 +1,43 @@
+package sessiontree_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/verte-zerg/gession/internal/session"
+	"github.com/verte-zerg/gession/internal/sessiontree"
+)
+
+func TestSearchEntitiesSortsByScore(t *testing.T) {
+	sessions := []*session.Session{
+		{ID: "$1", Name: "rapid"},
+		{ID: "$2", Name: "dotfiles"},
+		{ID: "$3", Name: "work/api"},
+		{ID: "$4", Name: "api"},
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{query: "", expected: []string{"rapid", "dotfiles", "work/api", "api"}},
+		{query: "api", expected: []string{"api", "work/api", "rapid"}},
+		{query: "API", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			vTree := sessiontree.New(true)
+			tree := vTree.SearchEntities(tt.query, sessions, 0, map[string]interface{}{})
+
+			names := make([]string, 0, len(tree))
+			for _, filteredSession := range tree {
+				names = append(names, filteredSession.Name)
+			}
+
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
@@ -14,6 +14,14 @@ func ConvertEventToCommand(e event.Event) Command {
 		return &tmuxCommandCapturePane{
 			PaneID: eventPane.PaneID,
 		}
+	case event.TypeCapturePaneHistory:
+		eventPane, ok := e.Data.(event.CapturePaneHistory)
+		assert.Assert(ok, "event.Data is not a EventCapturePaneHistory")
+
+		return &tmuxCommandCapturePaneHistory{
+			PaneID: eventPane.PaneID,
+			Lines:  eventPane.Lines,
+		}
 	case event.TypeListTree:
 		return &tmuxCommandListTree{}
 	default:
@@ -34,6 +42,16 @@ func ConvertCommandToEvent(command Command) event.Event {
 		}
 	}
 
+	if command, ok := command.(*tmuxCommandCapturePaneHistory); ok {
+		return event.Event{
+			Type: event.TypeCapturedPaneHistory,
+			Data: event.CapturedPaneHistory{
+				PaneID:  command.PaneID,
+				Content: command.Content,
+			},
+		}
+	}
+
 	if command, ok := command.(*tmuxCommandListTree); ok {
 		return event.Event{
 			Type: event.TypeListedTree,
@@ -46,3 +64,26 @@ func ConvertCommandToEvent(command Command) event.Event {
 	assert.Fatal("Unknown command type")
 	panic("Unreachable")
 }
+
+// ConvertFailedCommandToEvent creates an event for a command tmux replied to with an error.
+func ConvertFailedCommandToEvent(command Command, message string) event.Event {
+	requestType := event.Type("")
+
+	switch command.(type) {
+	case *tmuxCommandCapturePane:
+		requestType = event.TypeCapturePane
+	case *tmuxCommandCapturePaneHistory:
+		requestType = event.TypeCapturePaneHistory
+	case *tmuxCommandListTree:
+		requestType = event.TypeListTree
+	}
+
+	return event.Event{
+		Type: event.TypeCommandFailed,
+		Data: event.CommandFailed{
+			Request: requestType,
+			Command: command.GetCommand(false),
+			Message: message,
+		},
+	}
+}
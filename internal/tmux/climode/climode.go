@@ -1,13 +1,13 @@
 package climode
 
 import (
+	"errors"
 	"log/slog"
 	"os/exec"
 	"strings"
 
 	"github.com/verte-zerg/gession/internal/event"
 	"github.com/verte-zerg/gession/internal/tmux"
-	"github.com/verte-zerg/gession/pkg/assert"
 	"github.com/verte-zerg/gession/pkg/logging"
 )
 
@@ -21,12 +21,14 @@ type CLIMode struct {
 
 	commandsCh chan tmux.Command
 	resultsCh  chan tmux.Command
+	failuresCh chan event.Event
 }
 
 func New() *CLIMode {
 	return &CLIMode{
 		commandsCh:    make(chan tmux.Command, event.MaxQueue),
 		resultsCh:     make(chan tmux.Command, event.MaxQueue),
+		failuresCh:    make(chan event.Event, event.MaxQueue),
 		inputEventCh:  make(chan event.Event, event.MaxQueue),
 		outputEventCh: make(chan event.Event, event.MaxQueue),
 	}
@@ -59,8 +61,12 @@ func (t *CLIMode) eventReceiver() {
 
 func (t *CLIMode) eventSender() {
 	for {
-		command := <-t.resultsCh
-		t.outputEventCh <- tmux.ConvertCommandToEvent(command)
+		select {
+		case command := <-t.resultsCh:
+			t.outputEventCh <- tmux.ConvertCommandToEvent(command)
+		case e := <-t.failuresCh:
+			t.outputEventCh <- e
+		}
 	}
 }
 
@@ -71,14 +77,35 @@ func (t *CLIMode) handler() {
 		commandLine := command.GetCommand(false)
 		logger.Info("executing tmux command", slog.String("args", commandLine))
 
-		args := strings.Split(commandLine, " ")
+		// UTF-8 client (-u) gets control characters unsanitized, they are used as field separators
+		args := append([]string{"-u"}, strings.Split(commandLine, " ")...)
 
 		cmd := exec.Command("tmux", args...)
 
 		stdout, err := cmd.Output()
-		assert.Assert(err == nil, "Failed to execute tmux command output: %s", commandLine)
+		if err != nil {
+			message := err.Error()
 
-		command.SetResult(string(stdout))
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+				message = strings.TrimSpace(string(exitErr.Stderr))
+			}
+
+			logger.Error("tmux command failed", slog.String("args", commandLine), slog.String("error", message))
+
+			t.failuresCh <- tmux.ConvertFailedCommandToEvent(command, message)
+
+			continue
+		}
+
+		err = command.SetResult(string(stdout))
+		if err != nil {
+			logger.Error("could not handle tmux reply", slog.String("args", commandLine), slog.Any("error", err))
+
+			t.failuresCh <- tmux.ConvertFailedCommandToEvent(command, err.Error())
+
+			continue
+		}
 
 		t.resultsCh <- command
 	}
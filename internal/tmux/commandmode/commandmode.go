@@ -26,24 +26,29 @@ type CommandMode struct {
 	inputEventCh  chan event.Event
 	outputEventCh chan event.Event
 
-	commandsCh       chan tmux.Command
-	repeatCommandsCh chan tmux.Command
-	resultsCh        chan tmux.Command
+	commandsCh        chan tmux.Command
+	pendingCommandsCh chan tmux.Command
+	resultsCh         chan tmux.Command
+	failuresCh        chan event.Event
+	notificationsCh   chan event.Event
 }
 
 func New() *CommandMode {
 	return &CommandMode{
-		commandsCh:       make(chan tmux.Command, event.MaxQueue),
-		repeatCommandsCh: make(chan tmux.Command, event.MaxQueue),
-		resultsCh:        make(chan tmux.Command, event.MaxQueue),
-		inputEventCh:     make(chan event.Event, event.MaxQueue),
+		commandsCh:        make(chan tmux.Command, event.MaxQueue),
+		pendingCommandsCh: make(chan tmux.Command, event.MaxQueue),
+		resultsCh:         make(chan tmux.Command, event.MaxQueue),
+		failuresCh:        make(chan event.Event, event.MaxQueue),
+		notificationsCh:   make(chan event.Event, event.MaxQueue),
+		inputEventCh:      make(chan event.Event, event.MaxQueue),
 	}
 }
 
 func (t *CommandMode) Start() error {
 	logger.Info("starting tmux API")
 
-	cmd := exec.Command("tmux", "-C", "attach")
+	// UTF-8 client (-u) gets control characters unsanitized, they are used as field separators
+	cmd := exec.Command("tmux", "-u", "-C", "attach")
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
@@ -90,15 +95,22 @@ func (t *CommandMode) eventReciever() {
 		e := <-t.inputEventCh
 
 		command := tmux.ConvertEventToCommand(e)
+		// Command must be pending before it's sent, so its reply always finds it
+		t.pendingCommandsCh <- command
 		t.commandsCh <- command
-		t.repeatCommandsCh <- command
 	}
 }
 
 func (t *CommandMode) eventSender() {
 	for {
-		command := <-t.resultsCh
-		t.outputEventCh <- tmux.ConvertCommandToEvent(command)
+		select {
+		case command := <-t.resultsCh:
+			t.outputEventCh <- tmux.ConvertCommandToEvent(command)
+		case e := <-t.failuresCh:
+			t.outputEventCh <- e
+		case e := <-t.notificationsCh:
+			t.outputEventCh <- e
+		}
 	}
 }
 
@@ -116,47 +128,94 @@ func (t *CommandMode) commandReciever() {
 	}
 }
 
+// commandHandler reads tmux output and matches every `%begin`/`%end`/`%error` block with its command.
+// Commands are paired with blocks by the command number from the `%begin` line, blocks started not by gession
+// (e.g. the reply to the initial attach) are skipped.
 func (t *CommandMode) commandHandler() {
-	isCommandStarted := false
-	expectedEndLine := ""
+	inflightCommands := make(map[string]tmux.Command)
+
+	var currentGuard *guard
 
 	commandResult := strings.Builder{}
-	isFirstCommand := true
 
 	for t.scanner.Scan() {
 		line := t.scanner.Text()
 
-		if isCommandStarted {
-			if line == expectedEndLine {
-				if isFirstCommand {
-					isFirstCommand = false
-					isCommandStarted = false
+		if currentGuard != nil {
+			closingGuard, ok := parseGuard(line)
+			if !ok || closingGuard.number != currentGuard.number || closingGuard.kind == guardBegin {
+				commandResult.WriteString(line + "\n")
 
-					continue
-				}
+				continue
+			}
 
-				currentCommand := <-t.repeatCommandsCh
-				commandLine := currentCommand.GetCommand(true)
-				logger.Info("finish tmux command", slog.String("command", commandLine))
+			t.finishCommand(inflightCommands, closingGuard, commandResult.String())
 
-				currentCommand.SetResult(commandResult.String())
-				t.resultsCh <- currentCommand
+			currentGuard = nil
 
-				isCommandStarted = false
+			commandResult.Reset()
 
-				commandResult.Reset()
+			continue
+		}
 
-				continue
+		if beginGuard, ok := parseGuard(line); ok && beginGuard.kind == guardBegin {
+			currentGuard = &beginGuard
+
+			if beginGuard.isClientCommand() {
+				inflightCommands[beginGuard.number] = <-t.pendingCommandsCh
 			}
 
-			commandResult.WriteString(line + "\n")
+			continue
 		}
 
-		if !isCommandStarted && strings.HasPrefix(line, "%begin") {
-			expectedEndLine = strings.ReplaceAll(line, "%begin", "%end")
-			isCommandStarted = true
-
-			continue
+		if strings.HasPrefix(line, "%") {
+			t.handleNotification(line)
 		}
 	}
 }
+
+func (t *CommandMode) finishCommand(inflightCommands map[string]tmux.Command, closingGuard guard, result string) {
+	command, ok := inflightCommands[closingGuard.number]
+	if !ok {
+		logger.Info("skip tmux reply", slog.String("number", closingGuard.number))
+
+		return
+	}
+
+	delete(inflightCommands, closingGuard.number)
+
+	commandLine := command.GetCommand(true)
+
+	if closingGuard.kind == guardError {
+		message := strings.TrimSpace(result)
+		logger.Error("tmux command failed", slog.String("command", commandLine), slog.String("error", message))
+
+		t.failuresCh <- tmux.ConvertFailedCommandToEvent(command, message)
+
+		return
+	}
+
+	logger.Info("finish tmux command", slog.String("command", commandLine))
+
+	err := command.SetResult(result)
+	if err != nil {
+		logger.Error("could not handle tmux reply", slog.String("command", commandLine), slog.Any("error", err))
+
+		t.failuresCh <- tmux.ConvertFailedCommandToEvent(command, err.Error())
+
+		return
+	}
+
+	t.resultsCh <- command
+}
+
+func (t *CommandMode) handleNotification(line string) {
+	e, ok := tmux.ConvertNotificationToEvent(line)
+	if !ok {
+		return
+	}
+
+	logger.Info("tmux notification", slog.String("line", line), slog.String("type", string(e.Type)))
+
+	t.notificationsCh <- e
+}
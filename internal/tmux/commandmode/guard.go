@@ -0,0 +1,54 @@
+package commandmode
+
+import (
+	"strconv"
+	"strings"
+)
+
+type guardKind string
+
+const (
+	guardBegin guardKind = "%begin"
+	guardEnd   guardKind = "%end"
+	guardError guardKind = "%error"
+
+	guardPartsCount = 4
+	clientFlag      = 1
+)
+
+// guard is a line wrapping command output in control mode: `%begin|%end|%error <time> <number> <flags>`.
+type guard struct {
+	kind   guardKind
+	time   string
+	number string
+	flags  int
+}
+
+func parseGuard(line string) (guard, bool) {
+	parts := strings.Split(line, " ")
+	if len(parts) != guardPartsCount {
+		return guard{}, false
+	}
+
+	kind := guardKind(parts[0])
+	if kind != guardBegin && kind != guardEnd && kind != guardError {
+		return guard{}, false
+	}
+
+	flags, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return guard{}, false
+	}
+
+	return guard{
+		kind:   kind,
+		time:   parts[1],
+		number: parts[2],
+		flags:  flags,
+	}, true
+}
+
+// isClientCommand reports whether the block is a reply to a command sent by gession.
+func (g guard) isClientCommand() bool {
+	return g.flags&clientFlag != 0
+}
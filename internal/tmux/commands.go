@@ -1,12 +1,16 @@
 package tmux
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/verte-zerg/gession/internal/session"
 )
 
 type Command interface {
 	GetCommand(escaping bool) string
-	SetResult(content string)
+	SetResult(content string) error
 }
 
 // tmuxCommandCapturePane is a command to capture the content of a tmux pane.
@@ -19,8 +23,28 @@ func (t tmuxCommandCapturePane) GetCommand(_ bool) string {
 	return "capture-pane -p -e -t " + t.PaneID
 }
 
-func (t *tmuxCommandCapturePane) SetResult(result string) {
+func (t *tmuxCommandCapturePane) SetResult(result string) error {
 	t.Snapshot = result
+
+	return nil
+}
+
+// tmuxCommandCapturePaneHistory is a command to capture the plain text of a pane with its history.
+// Wrapped lines aren't joined, so the lines match the rows of the pane.
+type tmuxCommandCapturePaneHistory struct {
+	PaneID  string
+	Lines   int
+	Content string
+}
+
+func (t tmuxCommandCapturePaneHistory) GetCommand(_ bool) string {
+	return fmt.Sprintf("capture-pane -p -S -%d -t %s", t.Lines, t.PaneID)
+}
+
+func (t *tmuxCommandCapturePaneHistory) SetResult(result string) error {
+	t.Content = result
+
+	return nil
 }
 
 // tmuxCommandListTree is a command to list all tmux sessions, windows, and panes.
@@ -29,19 +53,23 @@ type tmuxCommandListTree struct {
 }
 
 func (t tmuxCommandListTree) GetCommand(escaping bool) string {
-	formatString := "#{session_name}|#{window_name}|#{pane_current_command}|#{window_index}.#{pane_index}|#{session_attached}.#{window_active}.#{pane_active}|#{session_last_attached}|#{session_id}.#{window_id}.#{pane_id}"
 	if escaping {
-		return "list-panes -a -F \"" + formatString + "\""
+		// Control mode reads commands as tmux command lines, so the separator has to be escaped
+		return "list-panes -a -F \"" + session.PaneFormat(`\037`) + "\""
 	}
 
-	return "list-panes -a -F " + formatString
+	return "list-panes -a -F " + session.PaneFormat(session.FieldSeparator)
 }
 
-func (t *tmuxCommandListTree) SetResult(result string) {
-	var err error
-	t.Sessions, err = session.ParseSessions(result[:len(result)-1])
-
+func (t *tmuxCommandListTree) SetResult(result string) error {
+	sessions, err := session.ParseSessions(strings.TrimSuffix(result, "\n"))
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("could not parse tmux tree: %w", err)
 	}
+
+	t.Sessions = slices.DeleteFunc(sessions, func(s *session.Session) bool {
+		return IsControlSession(s.Name)
+	})
+
+	return nil
 }
@@ -0,0 +1,141 @@
+package tmux
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+const (
+	// HookSessionChanged runs when a client switches to another session.
+	HookSessionChanged = "client-session-changed"
+	// HookSessionClosed runs when a session is killed or its last window is closed.
+	HookSessionClosed = "session-closed"
+
+	// recordHookMarker is in the commands of hooks installed by gession, so they are told apart from hooks of the user.
+	recordHookMarker = " record -hook "
+)
+
+// hookArguments are the formats the hooks pass to `gession record`.
+// The session of a closed session hook is another one, the closed session is only known by name.
+var hookArguments = map[string]string{
+	HookSessionChanged: "-d #{q:session_path} #{q:session_name}",
+	HookSessionClosed:  "#{q:hook_session_name}",
+}
+
+// hook is an entry of a global tmux hook, a hook is an array of commands run one by one.
+type hook struct {
+	index   int
+	command string
+}
+
+// InstallRecordHooks makes tmux report switches and closed sessions to `gession record`, so they are added to the history.
+// Hooks installed before are updated in place and other commands of the hooks are kept, so it can be run any number of times.
+func InstallRecordHooks(executable string) error {
+	for _, name := range []string{HookSessionChanged, HookSessionClosed} {
+		hooks, err := listHooks(name)
+		if err != nil {
+			return err
+		}
+
+		command := recordHookCommand(executable, name)
+		isInstalled := false
+
+		for _, h := range hooks {
+			if !strings.Contains(h.command, recordHookMarker) {
+				continue
+			}
+
+			// Duplicates could be left by older installs, only the first one is kept
+			if isInstalled {
+				_, err = runTmux("set-hook", "-gu", hookEntry(name, h.index))
+			} else {
+				_, err = runTmux("set-hook", "-g", hookEntry(name, h.index), command)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			isInstalled = true
+		}
+
+		if !isInstalled {
+			if _, err := runTmux("set-hook", "-ga", name, command); err != nil {
+				return err
+			}
+		}
+
+		logger.Info("installed hook", slog.String("hook", name), slog.Bool("updated", isInstalled))
+	}
+
+	return nil
+}
+
+// UninstallRecordHooks removes the hooks installed by gession, other commands of the hooks stay as they were.
+func UninstallRecordHooks() error {
+	for _, name := range []string{HookSessionChanged, HookSessionClosed} {
+		hooks, err := listHooks(name)
+		if err != nil {
+			return err
+		}
+
+		for _, h := range hooks {
+			if !strings.Contains(h.command, recordHookMarker) {
+				continue
+			}
+
+			if _, err := runTmux("set-hook", "-gu", hookEntry(name, h.index)); err != nil {
+				return err
+			}
+
+			logger.Info("uninstalled hook", slog.String("hook", name), slog.Int("index", h.index))
+		}
+	}
+
+	return nil
+}
+
+// listHooks returns the commands of the global hook, tmux lists them as `name[index] command` lines.
+func listHooks(name string) ([]hook, error) {
+	output, err := runTmux("show-hooks", "-g", name)
+	if err != nil {
+		return nil, err
+	}
+
+	hooks := make([]hook, 0)
+
+	for _, line := range strings.Split(output, "\n") {
+		entry, command, ok := strings.Cut(line, " ")
+		if !ok {
+			// A hook without commands is listed by its name only
+			continue
+		}
+
+		index, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(entry, name+"["), "]"))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse hook %q: %w", line, err)
+		}
+
+		hooks = append(hooks, hook{index: index, command: command})
+	}
+
+	return hooks, nil
+}
+
+func hookEntry(name string, index int) string {
+	return name + "[" + strconv.Itoa(index) + "]"
+}
+
+// recordHookCommand returns the tmux command of the hook running `gession record` in the background.
+// The executable is quoted for the shell, then `#` is escaped for formats and the result for a double-quoted tmux string.
+func recordHookCommand(executable, name string) string {
+	shellCommand := "'" + strings.ReplaceAll(executable, "'", `'\''`) + "'"
+	shellCommand = strings.ReplaceAll(shellCommand, "#", "##")
+	shellCommand += recordHookMarker + name + " " + hookArguments[name]
+
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`)
+
+	return `run-shell -b "` + escaper.Replace(shellCommand) + `"`
+}
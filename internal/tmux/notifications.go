@@ -0,0 +1,59 @@
+package tmux
+
+import (
+	"strings"
+
+	"github.com/verte-zerg/gession/internal/event"
+)
+
+const (
+	notificationPartsCount = 3
+)
+
+// ConvertNotificationToEvent converts a control mode notification line (e.g. `%window-close @1`) to an event.
+// The second value is false if the notification is not tracked by gession.
+func ConvertNotificationToEvent(line string) (event.Event, bool) {
+	parts := strings.SplitN(line, " ", notificationPartsCount)
+
+	switch parts[0] {
+	case "%sessions-changed", "%window-add", "%unlinked-window-add", "%layout-change":
+		return event.Event{Type: event.TypeTreeChanged}, true
+	case "%session-renamed":
+		if len(parts) < notificationPartsCount {
+			return event.Event{}, false
+		}
+
+		return event.Event{
+			Type: event.TypeSessionRenamed,
+			Data: event.SessionRenamed{
+				SessionID: parts[1],
+				Name:      parts[2],
+			},
+		}, true
+	case "%window-renamed", "%unlinked-window-renamed":
+		if len(parts) < notificationPartsCount {
+			return event.Event{}, false
+		}
+
+		return event.Event{
+			Type: event.TypeWindowRenamed,
+			Data: event.WindowRenamed{
+				WindowID: parts[1],
+				Name:     parts[2],
+			},
+		}, true
+	case "%window-close", "%unlinked-window-close":
+		if len(parts) < 2 { //nolint:mnd
+			return event.Event{}, false
+		}
+
+		return event.Event{
+			Type: event.TypeWindowClosed,
+			Data: event.WindowClosed{
+				WindowID: parts[1],
+			},
+		}, true
+	}
+
+	return event.Event{}, false
+}
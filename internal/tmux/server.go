@@ -0,0 +1,52 @@
+package tmux
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const (
+	// controlSessionPrefix marks sessions created by gession only to keep a tmux server alive.
+	controlSessionPrefix = "_gession_control_"
+)
+
+// HasSessions reports whether a tmux server is running and has at least one session to attach to.
+func HasSessions() bool {
+	output, err := exec.Command("tmux", "list-sessions", "-F", "#{session_id}").Output()
+
+	return err == nil && strings.TrimSpace(string(output)) != ""
+}
+
+// HasSession reports whether a session with exactly this name exists.
+func HasSession(name string) bool {
+	return exec.Command("tmux", "has-session", "-t", "="+name).Run() == nil
+}
+
+// StartServer starts a tmux server with a detached control session and returns the session name.
+func StartServer() (string, error) {
+	name := controlSessionPrefix + strconv.Itoa(os.Getpid())
+
+	output, err := exec.Command("tmux", "new-session", "-d", "-s", name).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("could not start tmux server: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return name, nil
+}
+
+// StopServer kills the control session. The server exits by itself if no other sessions were created.
+func StopServer(name string) {
+	err := exec.Command("tmux", "kill-session", "-t", name).Run()
+	if err != nil {
+		logger.Warn("could not kill control session", slog.String("name", name), slog.Any("error", err))
+	}
+}
+
+// IsControlSession reports whether the session was created by gession to bootstrap a tmux server.
+func IsControlSession(name string) bool {
+	return strings.HasPrefix(name, controlSessionPrefix)
+}
@@ -0,0 +1,189 @@
+package tmux
+
+import (
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/verte-zerg/gession/internal/sessiontemplate"
+)
+
+// CreateTemplatedSession creates a detached session with the windows and panes of the template.
+// If any step fails, the partly created session is killed, so a broken template doesn't leave it behind.
+// A live session with the same name is never touched, an error is returned instead.
+func CreateTemplatedSession(name, directory string, template *sessiontemplate.Template) error {
+	logger.Info("create templated session", slog.String("name", name), slog.String("directory", directory), slog.Int("windows", len(template.Windows)))
+
+	if HasSession(name) {
+		return fmt.Errorf("could not create session: a session named %q exists", name)
+	}
+
+	isCreated, err := applyTemplate(name, directory, template)
+	if err != nil {
+		// The session could be created by someone else in the meantime, only the one created here is killed
+		if !isCreated {
+			return err
+		}
+
+		if killErr := exec.Command("tmux", "kill-session", "-t", "="+name).Run(); killErr != nil {
+			logger.Warn("could not kill templated session", slog.String("name", name), slog.Any("error", killErr))
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// applyTemplate creates the session window by window, it reports whether the session was created even if a later step failed.
+func applyTemplate(name, directory string, template *sessiontemplate.Template) (bool, error) {
+	focusedWindowID := ""
+
+	for i, window := range template.Windows {
+		windowDirectory := sessiontemplate.ResolveDirectory(directory, window.Directory)
+
+		// The first pane is created with the window, so it's started in its own directory right away
+		firstPaneDirectory := windowDirectory
+		if len(window.Panes) > 0 {
+			firstPaneDirectory = sessiontemplate.ResolveDirectory(windowDirectory, window.Panes[0].Directory)
+		}
+
+		args := []string{"new-window", "-d", "-t", "=" + name + ":"}
+		if i == 0 {
+			args = append([]string{"new-session", "-d", "-s", name}, environmentArgs(template.Environment)...)
+		}
+
+		args = append(args, "-c", firstPaneDirectory, "-P", "-F", "#{window_id} #{pane_id}")
+		if window.Name != "" {
+			args = append(args, "-n", window.Name)
+		}
+
+		if len(window.Panes) > 0 {
+			args = append(args, scrollbackArgs(window.Panes[0].Scrollback)...)
+		}
+
+		output, err := runTmux(args...)
+		if err != nil {
+			return i > 0, fmt.Errorf("could not create window %d: %w", i, err)
+		}
+
+		windowID, paneID, _ := strings.Cut(output, " ")
+
+		err = applyWindow(windowID, paneID, windowDirectory, window)
+		if err != nil {
+			return true, fmt.Errorf("could not create window %d: %w", i, err)
+		}
+
+		if i == 0 || window.Focus {
+			focusedWindowID = windowID
+		}
+	}
+
+	_, err := runTmux("select-window", "-t", focusedWindowID)
+
+	return focusedWindowID != "", err
+}
+
+// applyWindow splits the window into the panes, starts their commands and applies the layout.
+func applyWindow(windowID, firstPaneID, windowDirectory string, window sessiontemplate.Window) error {
+	focusedPaneID := ""
+	previousPaneID := firstPaneID
+
+	for i, pane := range window.Panes {
+		paneID := firstPaneID
+
+		if i > 0 {
+			args := []string{"split-window", "-d", "-t", previousPaneID, "-c", sessiontemplate.ResolveDirectory(windowDirectory, pane.Directory), "-P", "-F", "#{pane_id}"}
+
+			if pane.Split == sessiontemplate.SplitHorizontal {
+				args = append(args, "-h")
+			} else {
+				args = append(args, "-v")
+			}
+
+			if pane.Size != "" {
+				args = append(args, "-l", pane.Size)
+			}
+
+			args = append(args, scrollbackArgs(pane.Scrollback)...)
+
+			var err error
+
+			paneID, err = runTmux(args...)
+			if err != nil {
+				return fmt.Errorf("could not split pane %d: %w", i, err)
+			}
+		}
+
+		if pane.Command != "" {
+			// Commands are typed into the shell, so the pane isn't closed when the command exits
+			if _, err := runTmux("send-keys", "-t", paneID, "-l", pane.Command); err != nil {
+				return err
+			}
+
+			if _, err := runTmux("send-keys", "-t", paneID, "Enter"); err != nil {
+				return err
+			}
+		}
+
+		if pane.Focus {
+			focusedPaneID = paneID
+		}
+
+		previousPaneID = paneID
+	}
+
+	// A saved layout doesn't fit if the panes changed, the panes are kept with the default layout then
+	if window.Layout != "" {
+		if _, err := runTmux("select-layout", "-t", windowID, window.Layout); err != nil {
+			logger.Warn("could not apply layout", slog.String("windowID", windowID), slog.Any("error", err))
+		}
+	}
+
+	if focusedPaneID != "" {
+		if _, err := runTmux("select-pane", "-t", focusedPaneID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scrollbackArgs returns the command of a pane printing the scrollback file before its shell starts.
+// The file is passed as an argument, so its path isn't parsed by the shell.
+func scrollbackArgs(scrollbackPath string) []string {
+	if scrollbackPath == "" {
+		return nil
+	}
+
+	script := `cat -- "$0"; rm -f -- "$0"; rmdir -- "${0%/*}" 2>/dev/null; exec "${SHELL:-/bin/sh}" -l`
+
+	return []string{"sh", "-c", script, scrollbackPath}
+}
+
+func environmentArgs(environment map[string]string) []string {
+	keys := make([]string, 0, len(environment))
+	for key := range environment {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	args := make([]string, 0, len(keys)*2) //nolint:mnd
+	for _, key := range keys {
+		args = append(args, "-e", key+"="+environment[key])
+	}
+
+	return args
+}
+
+func runTmux(args ...string) (string, error) {
+	output, err := exec.Command("tmux", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("could not run tmux %s: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
@@ -1,14 +1,26 @@
 package tmux
 
 import (
+	"fmt"
+	"github.com/verte-zerg/gession/internal/session"
 	"github.com/verte-zerg/gession/pkg/assert"
+	"github.com/verte-zerg/gession/pkg/logging"
+	"os"
 	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
 )
 
-func CreateTmuxSession(name string, directory string) {
-	tmux := exec.Command("tmux", "new-session", "-d", "-s", name, "-c", directory)
-	err := tmux.Run()
-	assert.Assert(err == nil, "Failed to create tmux session")
+var (
+	logger = logging.GetInstance().WithGroup("tmux")
+)
+
+// CreateTmuxSession creates a detached session, it fails if a session with the name exists.
+func CreateTmuxSession(name string, directory string) error {
+	_, err := runTmux("new-session", "-d", "-s", name, "-c", directory)
+
+	return err
 }
 
 func CreateTmuxWindow(sessionName, name, directory string) {
@@ -23,6 +35,19 @@ func SwitchClient(entityID string) {
 	assert.Assert(err == nil, "Failed to switch tmux session")
 }
 
+// AttachSession replaces the current process with a tmux client attached to the entity.
+// It returns only if the client could not be started.
+func AttachSession(entityID string) error {
+	tmuxPath, err := exec.LookPath("tmux")
+	if err != nil {
+		return fmt.Errorf("could not find tmux: %w", err)
+	}
+
+	err = syscall.Exec(tmuxPath, []string{"tmux", "attach-session", "-t", entityID}, os.Environ())
+
+	return fmt.Errorf("could not attach tmux session: %w", err)
+}
+
 func KillTmuxSession(sessionID string) {
 	tmux := exec.Command("tmux", "kill-session", "-t", sessionID)
 	err := tmux.Run()
@@ -46,3 +71,45 @@ func RenameTmuxWindow(windowID, newName string) {
 	err := tmux.Run()
 	assert.Assert(err == nil, "Failed to rename tmux window")
 }
+
+// ListSessions returns the tree of live sessions, control sessions are skipped.
+func ListSessions() ([]*session.Session, error) {
+	command := &tmuxCommandListTree{}
+
+	// UTF-8 client (-u) gets control characters unsanitized, they are used as field separators
+	args := append([]string{"-u"}, strings.Split(command.GetCommand(false), " ")...)
+
+	output, err := exec.Command("tmux", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not list tmux sessions: %w", err)
+	}
+
+	err = command.SetResult(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	return command.Sessions, nil
+}
+
+// CapturePaneHistory returns the whole content of the pane with its scrollback and colors.
+// Wrapped lines are joined, so the content is wrapped again to the size of the pane it's printed in.
+func CapturePaneHistory(paneID string) (string, error) {
+	output, err := exec.Command("tmux", "capture-pane", "-p", "-e", "-J", "-S", "-", "-t", paneID).Output()
+	if err != nil {
+		return "", fmt.Errorf("could not capture pane %s: %w", paneID, err)
+	}
+
+	return string(output), nil
+}
+
+// ScrollPane enters copy mode in the pane and scrolls it up by the number of rows.
+func ScrollPane(paneID string, rows int) error {
+	if _, err := runTmux("copy-mode", "-t", paneID); err != nil {
+		return err
+	}
+
+	_, err := runTmux("send-keys", "-t", paneID, "-X", "goto-line", strconv.Itoa(rows))
+
+	return err
+}
@@ -5,13 +5,17 @@ import "fmt"
 type mode string
 
 const (
-	normalMode mode = "normal"
-	renameMode mode = "rename"
-	newMode    mode = "new"
-
-	normalModePrompt = "input > "
-	renameModePrompt = "rename %s to > "
-	newModePrompt    = "new session name > "
+	normalMode   mode = "normal"
+	renameMode   mode = "rename"
+	newMode      mode = "new"
+	worktreeMode mode = "worktree"
+	searchMode   mode = "search"
+
+	normalModePrompt   = "input > "
+	renameModePrompt   = "rename %s to > "
+	newModePrompt      = "new session name > "
+	worktreeModePrompt = "new worktree of %s, branch > "
+	searchModePrompt   = "search content > "
 )
 
 type modeState struct {
@@ -1,14 +1,21 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/verte-zerg/gession/internal/contentsearch"
 	"github.com/verte-zerg/gession/internal/event"
+	"github.com/verte-zerg/gession/internal/gitstatus"
+	"github.com/verte-zerg/gession/internal/hibernate"
+	"github.com/verte-zerg/gession/internal/history"
 	"github.com/verte-zerg/gession/internal/printer"
 	"github.com/verte-zerg/gession/internal/session"
+	"github.com/verte-zerg/gession/internal/sessiontemplate"
 	"github.com/verte-zerg/gession/internal/sessiontree"
 	"github.com/verte-zerg/gession/internal/tmux"
 	"github.com/verte-zerg/gession/pkg/assert"
@@ -20,6 +27,15 @@ type Kind int
 const (
 	NormalKind Kind = iota
 	PrimeKind
+	// CombinedKind lists live sessions first and then prime folders without a session.
+	CombinedKind
+)
+
+const (
+	clearScreen = "\033[H\033[2J"
+
+	// contentSearchLines is how many lines of the pane history are searched.
+	contentSearchLines = 2000
 )
 
 var (
@@ -32,46 +48,103 @@ type TUI struct {
 	mode       mode
 	modeStates map[mode]*modeState
 
-	sessions    []*session.Session
-	tmpSessions []*session.Session
-	directory   string
+	sessions      []*session.Session
+	liveSessions  []*session.Session
+	primeSessions []*session.Session
+	isTreeListed  bool
+	isPrimeListed bool
+	directory     string
+
+	// hibernatedSessions are listed after the live sessions in normal and combined modes.
+	hibernatedSessions []*session.Session
+
+	isTreeRequested    bool
+	isTreeRefreshDirty bool
 
 	printer *printer.Printer
 	vTree   *sessiontree.VisualizeTree
 
-	sessionIDToSession map[string]*session.Session
-	paneIDToSession    map[string]*session.Session
+	sessionIDToSession      map[string]*session.Session
+	paneIDToSession         map[string]*session.Session
+	primeSessionIDToSession map[string]*session.Session
+	resolvedPaths           map[string]string
+
+	// gitStatuses are statuses of checked directories, nil for directories that aren't git repositories.
+	gitStatuses map[string]*session.GitStatus
+	// gitErrors are messages of directories whose status couldn't be checked, they aren't known to be repositories or not.
+	gitErrors map[string]string
 
 	selectedIdx int
 
+	status string
+
+	// templatesDir is where session templates are looked up if the directory has no template.
+	templatesDir string
+	// hibernateDir is where hibernated sessions are saved.
+	hibernateDir string
+
+	// trusted are the project templates allowed to run, pendingTrust is the one the user is asked to trust.
+	trusted      *sessiontemplate.Trusted
+	trustedPath  string
+	pendingTrust *sessiontemplate.UntrustedError
+
+	// history is the log of switches and creates, sessions and folders are ordered by its frecency.
+	history     *history.History
+	historyPath string
+
+	// contentIndex is the captured text of the panes, it's filled when the content search starts.
+	contentIndex *contentsearch.Index
+	// capturingPanes are requested for the content search and not captured yet.
+	capturingPanes map[string]contentsearch.Pane
+	hits           []contentsearch.Hit
+	selectedHitIdx int
+
 	unwrappedSession map[string]interface{}
 
+	exitHooks []func()
+
 	eventInputCh  chan event.Event
 	eventOutputCh chan event.Event
 }
 
-func NewTUI(width, height int, kind Kind, directory string) *TUI {
+func NewTUI(width, height int, kind Kind, directory, templatesDir, hibernateDir, trustedPath, historyPath string) *TUI {
 	isPrimeKind := kind == PrimeKind
 
 	return &TUI{
-		kind:             kind,
-		sessions:         make([]*session.Session, 0),
-		tmpSessions:      nil,
-		directory:        directory,
-		eventInputCh:     make(chan event.Event, event.MaxQueue),
-		unwrappedSession: make(map[string]interface{}),
-		printer:          printer.New(width, height, isPrimeKind),
-		vTree:            sessiontree.New(isPrimeKind),
-		mode:             normalMode,
+		kind:                    kind,
+		sessions:                make([]*session.Session, 0),
+		primeSessionIDToSession: make(map[string]*session.Session),
+		directory:               directory,
+		templatesDir:            templatesDir,
+		hibernateDir:            hibernateDir,
+		trustedPath:             trustedPath,
+		historyPath:             historyPath,
+		eventInputCh:            make(chan event.Event, event.MaxQueue),
+		unwrappedSession:        make(map[string]interface{}),
+		resolvedPaths:           make(map[string]string),
+		gitStatuses:             make(map[string]*session.GitStatus),
+		gitErrors:               make(map[string]string),
+		printer:                 printer.New(width, height, isPrimeKind),
+		vTree:                   sessiontree.New(isPrimeKind),
+		mode:                    normalMode,
 		modeStates: map[mode]*modeState{
-			normalMode: {prompt: normalModePrompt},
-			renameMode: {prompt: renameModePrompt},
-			newMode:    {prompt: newModePrompt},
+			normalMode:   {prompt: normalModePrompt},
+			renameMode:   {prompt: renameModePrompt},
+			newMode:      {prompt: newModePrompt},
+			worktreeMode: {prompt: worktreeModePrompt},
+			searchMode:   {prompt: searchModePrompt},
 		},
 	}
 }
 
 func (tui *TUI) Start() {
+	tui.loadHistory()
+	tui.loadTrusted()
+
+	if tui.kind != PrimeKind {
+		tui.loadHibernatedSessions()
+	}
+
 	go tui.eventReciever()
 }
 
@@ -87,14 +160,22 @@ func (tui *TUI) Render() {
 	logger.Info("render")
 
 	ms := tui.modeStates[tui.mode]
-	frame := tui.printer.GenerateFrame(tui.vTree, ms.getPrompt()+string(ms.input))
+
+	var frame string
+
+	if tui.mode == searchMode {
+		frame = tui.printer.GenerateSearchFrame(tui.hits, tui.selectedHitIdx, tui.contentIndex.Len(), ms.getPrompt()+string(ms.input), tui.status)
+	} else {
+		frame = tui.printer.GenerateFrame(tui.vTree, ms.getPrompt()+string(ms.input), tui.status)
+	}
+
 	fmt.Print(frame) //nolint:forbidigo
 
 	logger.Info("rendered")
 }
 
 func (tui *TUI) requestSessionPreview(sessionID string) {
-	if tui.kind == PrimeKind {
+	if tui.kind == PrimeKind || isFolderID(sessionID) || isHibernatedID(sessionID) {
 		return
 	}
 
@@ -126,13 +207,47 @@ func (tui *TUI) eventReciever() {
 			assert.Assert(ok, "Event data is not a TmuxCommandListTree")
 			tui.handleListedTree(sessions.Sessions)
 		case event.TypeListedFolders:
-			sessions, ok := inputEvent.Data.([]*session.Session)
+			listed, ok := inputEvent.Data.(event.ListedFolders)
 			assert.Assert(ok, "Event data is not a EventListedFolders")
-			tui.handleListedFolders(sessions)
+			tui.handleListedFolders(listed)
+		case event.TypeRemovedFolders:
+			removed, ok := inputEvent.Data.(event.RemovedFolders)
+			assert.Assert(ok, "Event data is not a EventRemovedFolders")
+			tui.handleRemovedFolders(removed)
+		case event.TypeFoldersFailed:
+			failed, ok := inputEvent.Data.(event.FoldersFailed)
+			assert.Assert(ok, "Event data is not a EventFoldersFailed")
+			tui.handleFoldersFailed(failed)
 		case event.TypeCapturedPane:
 			eventPane, ok := inputEvent.Data.(event.CapturedPane)
 			assert.Assert(ok, "Event data is not a EventCapturedPane")
 			tui.handleCapturedPane(eventPane.PaneID, eventPane.Snapshot)
+		case event.TypeCapturedPaneHistory:
+			captured, ok := inputEvent.Data.(event.CapturedPaneHistory)
+			assert.Assert(ok, "Event data is not a EventCapturedPaneHistory")
+			tui.handleCapturedPaneHistory(captured.PaneID, captured.Content)
+		case event.TypeCheckedGitStatus:
+			checked, ok := inputEvent.Data.(event.CheckedGitStatus)
+			assert.Assert(ok, "Event data is not a EventCheckedGitStatus")
+			tui.handleCheckedGitStatus(checked)
+		case event.TypeCommandFailed:
+			failure, ok := inputEvent.Data.(event.CommandFailed)
+			assert.Assert(ok, "Event data is not a EventCommandFailed")
+			tui.handleCommandFailed(failure)
+		case event.TypeTreeChanged:
+			tui.requestTree()
+		case event.TypeSessionRenamed:
+			renamed, ok := inputEvent.Data.(event.SessionRenamed)
+			assert.Assert(ok, "Event data is not a EventSessionRenamed")
+			tui.handleSessionRenamed(renamed.SessionID, renamed.Name)
+		case event.TypeWindowRenamed:
+			renamed, ok := inputEvent.Data.(event.WindowRenamed)
+			assert.Assert(ok, "Event data is not a EventWindowRenamed")
+			tui.handleWindowRenamed(renamed.WindowID, renamed.Name)
+		case event.TypeWindowClosed:
+			closed, ok := inputEvent.Data.(event.WindowClosed)
+			assert.Assert(ok, "Event data is not a EventWindowClosed")
+			tui.handleWindowClosed(closed.WindowID)
 		default:
 			assert.Fatal("Unknown event type")
 		}
@@ -143,28 +258,434 @@ func (tui *TUI) sendEvent(e event.Event) {
 	tui.eventOutputCh <- e
 }
 
-//nolint:cyclop,gocognit,funlen
-func (tui *TUI) handleCommand(input string, isDelete bool) {
-	selectedSession := tui.vTree.GetSelectedSession()
-	selectedWindow := tui.vTree.GetSelectedWindow()
+// requestTree asks tmux for a fresh tree. Requests made while one is in flight are coalesced into a single one.
+func (tui *TUI) requestTree() {
+	if tui.isTreeRequested {
+		tui.isTreeRefreshDirty = true
 
-	if os.Getenv("TMUX") == "" {
-		os.Exit(1)
+		return
 	}
 
-	if tui.kind == PrimeKind {
-		if selectedSession == nil {
+	tui.isTreeRequested = true
+	tui.sendEvent(event.Event{
+		Type: event.TypeListTree,
+	})
+}
+
+// requestGitStatus asks for git statuses of the sessions directories.
+// Directories that were checked already are skipped unless isRefresh is set.
+func (tui *TUI) requestGitStatus(sessions []*session.Session, isRefresh bool) {
+	directories := make([]string, 0, len(sessions))
+
+	for _, session := range sessions {
+		if _, ok := tui.gitStatuses[session.Directory]; ok && !isRefresh {
+			continue
+		}
+
+		directories = append(directories, session.Directory)
+	}
+
+	if len(directories) == 0 {
+		return
+	}
+
+	tui.sendEvent(event.Event{
+		Type: event.TypeCheckGitStatus,
+		Data: event.CheckGitStatus{
+			Directories: directories,
+		},
+	})
+}
+
+// AddExitHook registers a function to run right before gession exits.
+func (tui *TUI) AddExitHook(hook func()) {
+	tui.exitHooks = append(tui.exitHooks, hook)
+}
+
+func (tui *TUI) runExitHooks() {
+	for _, hook := range tui.exitHooks {
+		hook()
+	}
+}
+
+func (tui *TUI) exit(code int) {
+	logger.Info("Exiting application", slog.Int("code", code))
+
+	tui.runExitHooks()
+	os.Exit(code)
+}
+
+// switchTo switches the tmux client to the entity and exits.
+// Outside of tmux there is no client to switch, so gession is replaced with a new client attached to the entity.
+func (tui *TUI) switchTo(entityID string) {
+	if os.Getenv("TMUX") != "" {
+		tmux.SwitchClient(entityID)
+		tui.exit(0)
+	}
+
+	logger.Info("Attaching to tmux", slog.String("entityID", entityID))
+
+	tui.runExitHooks()
+	fmt.Print(clearScreen) //nolint:forbidigo
+
+	err := tmux.AttachSession(entityID)
+	assert.Fatal("could not attach to tmux: %v", err)
+}
+
+// handlePrimeCommand switches to the folder or worktree, creating a session if there is none.
+func (tui *TUI) handlePrimeCommand(input string, selectedSession *sessiontree.FilteredSession, selectedWindow *sessiontree.FilteredWindow) {
+	if selectedSession == nil {
+		return
+	}
+
+	switch {
+	case tui.mode == worktreeMode:
+		tui.createWorktree(selectedSession, input)
+	case selectedWindow != nil:
+		tui.switchToDirectory(worktreeSessionName(selectedSession.Name, selectedWindow.Name), selectedWindow.Directory)
+	case isFolderID(selectedSession.ID):
+		tui.createAndSwitchTo(selectedSession.Name, selectedSession.Directory, selectedSession.Template)
+	default:
+		if selectedSession.LinkedSession != nil {
+			tui.recordVisit(history.ActionSwitch, selectedSession.LinkedSession.Name, selectedSession.Directory)
+		}
+
+		tui.switchTo(selectedSession.ID)
+	}
+}
+
+// switchToDirectory switches to a live session started in the directory, or creates one with the name.
+func (tui *TUI) switchToDirectory(name, directory string) {
+	for _, liveSession := range tui.liveSessions {
+		if liveSession.Directory != "" && tui.resolvePath(liveSession.Directory) == tui.resolvePath(directory) {
+			tui.recordVisit(history.ActionSwitch, liveSession.Name, directory)
+			tui.switchTo(liveSession.ID)
+
 			return
 		}
+	}
+
+	tui.createAndSwitchTo(name, directory, nil)
+}
+
+// createAndSwitchTo creates a session from the template and switches to it.
+// Without a template, the template found for the session is used, or a bare session is created if there is none.
+// If the session can't be created, e.g. a session with the name is started in another directory, the error is shown and the TUI stays open.
+func (tui *TUI) createAndSwitchTo(name, directory string, template *sessiontemplate.Template) {
+	if liveSession := tui.liveSessionNamed(name); liveSession != nil {
+		tui.status = fmt.Sprintf("session %s already exists in %s", name, liveSession.Directory)
+
+		return
+	}
+
+	var err error
+
+	if template == nil {
+		template, err = tui.findTemplate(directory, name)
+	}
+
+	if err == nil && template == nil {
+		err = tmux.CreateTmuxSession(name, directory)
+	} else if err == nil {
+		err = tmux.CreateTemplatedSession(name, directory, template)
+	}
+
+	if err != nil {
+		logger.Error("could not create session", slog.String("name", name), slog.Any("error", err))
+
+		tui.status = err.Error()
+
+		return
+	}
+
+	tui.recordVisit(history.ActionCreate, name, directory)
+	tui.switchTo(name)
+}
+
+// findTemplate returns the template for the session. An untrusted project template isn't used until Enter is pressed again for it,
+// then it's trusted and its commands are run.
+func (tui *TUI) findTemplate(directory, name string) (*sessiontemplate.Template, error) {
+	template, err := sessiontemplate.Find(directory, name, tui.templatesDir, tui.trusted)
+
+	untrusted := &sessiontemplate.UntrustedError{}
+	if !errors.As(err, &untrusted) {
+		tui.pendingTrust = nil
+
+		return template, err
+	}
+
+	if tui.pendingTrust == nil || *tui.pendingTrust != *untrusted {
+		tui.pendingTrust = untrusted
+
+		return nil, fmt.Errorf("%w, press enter again to trust it and run its commands", err)
+	}
+
+	tui.pendingTrust = nil
+
+	logger.Info("trust project template", slog.String("path", untrusted.Path), slog.String("hash", untrusted.Hash))
+
+	if err := tui.trusted.Trust(untrusted.Path, untrusted.Hash); err != nil {
+		return nil, err
+	}
+
+	return sessiontemplate.Find(directory, name, tui.templatesDir, tui.trusted)
+}
+
+// createWorktree creates a worktree for the branch in the repository of the folder and switches to a new session in it.
+func (tui *TUI) createWorktree(selectedSession *sessiontree.FilteredSession, branch string) {
+	if branch == "" {
+		return
+	}
+
+	logger.Info("create worktree", slog.String("directory", selectedSession.Directory), slog.String("branch", branch))
+
+	worktreePath, err := gitstatus.AddWorktree(selectedSession.Directory, branch)
+	if err != nil {
+		logger.Error("could not create worktree", slog.Any("error", err))
+
+		tui.status = err.Error()
+		tui.modeStates[worktreeMode].reset()
+		tui.mode = normalMode
+
+		return
+	}
+
+	tui.switchToDirectory(worktreeSessionName(selectedSession.Name, branch), worktreePath)
+}
+
+// loadHibernatedSessions reads the hibernated sessions, they are listed on the next rebuild.
+func (tui *TUI) loadHibernatedSessions() {
+	records, err := hibernate.List(tui.hibernateDir)
+	if err != nil {
+		logger.Error("could not list hibernated sessions", slog.Any("error", err))
+
+		tui.status = err.Error()
+	}
+
+	tui.hibernatedSessions = make([]*session.Session, 0, len(records))
+	for _, record := range records {
+		tui.hibernatedSessions = append(tui.hibernatedSessions, record.Session())
+	}
+}
+
+// hibernateSession saves the layout and scrollback of the session to disk and kills it.
+// The session is listed as hibernated until it's woken or deleted.
+func (tui *TUI) hibernateSession(sessionID string) {
+	liveSession := tui.sessionIDToSession[sessionID]
+
+	err := hibernate.Save(tui.hibernateDir, liveSession)
+	if err != nil {
+		logger.Error("could not hibernate session", slog.String("sessionID", sessionID), slog.Any("error", err))
+
+		tui.status = err.Error()
+
+		return
+	}
+
+	logger.Info("kill hibernated session", slog.String("sessionID", sessionID), slog.String("sessionName", liveSession.Name))
+	tmux.KillTmuxSession(sessionID)
+
+	tui.removeLiveSession(sessionID)
+	tui.loadHibernatedSessions()
+	tui.rebuildSessions()
+}
+
+// handleHibernatedCommand wakes the hibernated session and switches to it, or deletes it with its scrollback.
+func (tui *TUI) handleHibernatedCommand(selectedSession *sessiontree.FilteredSession, isDelete bool) {
+	if isDelete {
+		err := hibernate.Remove(tui.hibernateDir, selectedSession.Name)
+		if err != nil {
+			tui.status = err.Error()
+		}
 
-		if strings.HasPrefix(selectedSession.ID, "notexisted_") {
-			tmux.CreateTmuxSession(selectedSession.Name, selectedSession.Directory)
-			tmux.SwitchClient(selectedSession.Name)
+		tui.loadHibernatedSessions()
+		tui.rebuildSessions()
 
-			os.Exit(0)
+		return
+	}
+
+	err := hibernate.Wake(tui.hibernateDir, selectedSession.Name)
+	if err != nil {
+		logger.Error("could not wake session", slog.String("name", selectedSession.Name), slog.Any("error", err))
+
+		tui.status = err.Error()
+
+		return
+	}
+
+	tui.recordVisit(history.ActionCreate, selectedSession.Name, selectedSession.Directory)
+	tui.switchTo(selectedSession.Name)
+}
+
+// loadHistory reads the history of visits, sessions are ordered by it when they are listed.
+func (tui *TUI) loadHistory() {
+	var err error
+
+	tui.history, err = history.Load(tui.historyPath)
+	if err != nil {
+		logger.Error("could not load history", slog.Any("error", err))
+
+		tui.status = err.Error()
+	}
+}
+
+// loadTrusted reads the trusted project templates, an unreadable list trusts nothing.
+func (tui *TUI) loadTrusted() {
+	var err error
+
+	tui.trusted, err = sessiontemplate.LoadTrusted(tui.trustedPath)
+	if err != nil {
+		logger.Error("could not load trusted templates", slog.Any("error", err))
+
+		tui.status = err.Error()
+	}
+}
+
+// recordVisit adds the switch or create to the history. The directory is resolved, so the folder is found by it in prime mode.
+func (tui *TUI) recordVisit(action history.Action, name, directory string) {
+	if directory != "" {
+		directory = tui.resolvePath(directory)
+	}
+
+	err := tui.history.Record(history.Entry{
+		Time:      time.Now(),
+		Action:    action,
+		Name:      name,
+		Directory: directory,
+	})
+	if err != nil {
+		logger.Error("could not record history", slog.String("name", name), slog.Any("error", err))
+	}
+}
+
+// liveSessionNamed returns the live session with exactly the name, nil if there is none.
+func (tui *TUI) liveSessionNamed(name string) *session.Session {
+	for _, liveSession := range tui.liveSessions {
+		if liveSession.Name == name {
+			return liveSession
 		}
+	}
+
+	return nil
+}
 
-		tmux.SwitchClient(selectedSession.ID)
+func (tui *TUI) removeLiveSession(sessionID string) {
+	newSessions := make([]*session.Session, 0)
+
+	for _, session := range tui.liveSessions {
+		if session.ID != sessionID {
+			newSessions = append(newSessions, session)
+		}
+	}
+
+	tui.liveSessions = newSessions
+}
+
+// isHibernatedID reports whether the ID is of a hibernated session.
+func isHibernatedID(id string) bool {
+	return strings.HasPrefix(id, hibernate.IDPrefix)
+}
+
+// isFolderID reports whether the ID is of a prime folder that has no session yet.
+func isFolderID(id string) bool {
+	return strings.HasPrefix(id, "notexisted_")
+}
+
+func worktreeSessionName(folderName, worktreeName string) string {
+	return session.SanitizeName(folderName + "@" + worktreeName)
+}
+
+// startContentSearch captures the history of every pane, the search runs on the panes captured so far.
+func (tui *TUI) startContentSearch() {
+	tui.mode = searchMode
+	tui.contentIndex = contentsearch.NewIndex()
+	tui.capturingPanes = make(map[string]contentsearch.Pane)
+	tui.hits = nil
+	tui.selectedHitIdx = 0
+
+	requests := make([]event.Event, 0)
+
+	for _, liveSession := range tui.liveSessions {
+		for _, window := range liveSession.Windows {
+			for _, pane := range window.Panes {
+				tui.capturingPanes[pane.ID] = contentsearch.Pane{
+					ID:          pane.ID,
+					SessionName: liveSession.Name,
+					WindowName:  window.Name,
+					Index:       pane.Index,
+					Height:      pane.Height,
+				}
+
+				requests = append(requests, event.Event{
+					Type: event.TypeCapturePaneHistory,
+					Data: event.CapturePaneHistory{
+						PaneID: pane.ID,
+						Lines:  contentSearchLines,
+					},
+				})
+			}
+		}
+	}
+
+	logger.Info("start content search", slog.Int("panes", len(requests)))
+
+	// Requests are sent in the background, so captured panes are received while there are more requests than the queue holds
+	go func() {
+		for _, request := range requests {
+			tui.sendEvent(request)
+		}
+	}()
+}
+
+// searchContent finds the query of the search mode in the captured panes.
+func (tui *TUI) searchContent() {
+	tui.hits = tui.contentIndex.Search(string(tui.modeStates[searchMode].input))
+	tui.selectedHitIdx = max(0, min(tui.selectedHitIdx, len(tui.hits)-1))
+}
+
+// jumpToHit switches to the pane of the selected hit, scrolling the pane to the hit if it's in the history.
+func (tui *TUI) jumpToHit() {
+	if tui.selectedHitIdx >= len(tui.hits) {
+		return
+	}
+
+	hit := tui.hits[tui.selectedHitIdx]
+
+	if offset := hit.ScrollOffset(); offset > 0 {
+		if err := tmux.ScrollPane(hit.Pane.ID, offset); err != nil {
+			logger.Warn("could not scroll pane to the hit", slog.String("paneID", hit.Pane.ID), slog.Any("error", err))
+		}
+	}
+
+	if liveSession, ok := tui.paneIDToSession[hit.Pane.ID]; ok {
+		tui.recordVisit(history.ActionSwitch, liveSession.Name, liveSession.Directory)
+	}
+
+	tui.switchTo(hit.Pane.ID)
+}
+
+//nolint:cyclop,gocognit,funlen
+func (tui *TUI) handleCommand(input string, isDelete bool) {
+	if tui.mode == searchMode {
+		tui.jumpToHit()
+
+		return
+	}
+
+	selectedSession := tui.vTree.GetSelectedSession()
+	selectedWindow := tui.vTree.GetSelectedWindow()
+
+	// In combined mode folders without a session are created like in prime mode
+	isFolderSelected := tui.mode == normalMode && !isDelete && selectedSession != nil && isFolderID(selectedSession.ID)
+
+	if tui.kind == PrimeKind || isFolderSelected {
+		tui.handlePrimeCommand(input, selectedSession, selectedWindow)
+
+		return
+	}
+
+	if tui.mode == normalMode && selectedSession != nil && isHibernatedID(selectedSession.ID) {
+		tui.handleHibernatedCommand(selectedSession, isDelete)
 
 		return
 	}
@@ -180,13 +701,11 @@ func (tui *TUI) handleCommand(input string, isDelete bool) {
 					entityID = selectedWindow.ID
 				}
 
-				tmux.SwitchClient(entityID)
-				os.Exit(0)
+				tui.recordVisit(history.ActionSwitch, selectedSession.Name, selectedSession.Directory)
+				tui.switchTo(entityID)
 			}
 
-			tmux.CreateTmuxSession(sessionName, tui.directory)
-			tmux.SwitchClient(sessionName)
-			os.Exit(0)
+			tui.createAndSwitchTo(sessionName, tui.directory, nil)
 		}
 
 		if selectedSession != nil {
@@ -211,15 +730,8 @@ func (tui *TUI) handleCommand(input string, isDelete bool) {
 			logger.Info("kill session", slog.String("sessionID", selectedSession.ID), slog.String("sessionName", selectedSession.Name))
 			tmux.KillTmuxSession(selectedSession.ID)
 
-			newSessions := make([]*session.Session, 0)
-
-			for _, session := range tui.sessions {
-				if session.ID != selectedSession.ID {
-					newSessions = append(newSessions, session)
-				}
-			}
-
-			tui.sessions = newSessions
+			tui.removeLiveSession(selectedSession.ID)
+			tui.rebuildSessions()
 		}
 	case renameMode:
 		if input != "" {
@@ -246,9 +758,7 @@ func (tui *TUI) handleCommand(input string, isDelete bool) {
 		}
 	case newMode:
 		if input != "" {
-			tmux.CreateTmuxSession(input, tui.directory)
-			tmux.SwitchClient(input)
-			os.Exit(0)
+			tui.createAndSwitchTo(input, tui.directory, nil)
 		}
 	}
 }
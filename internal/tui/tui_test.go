@@ -0,0 +1,173 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/verte-zerg/gession/internal/event"
+	"github.com/verte-zerg/gession/internal/key"
+	"github.com/verte-zerg/gession/internal/session"
+	"github.com/verte-zerg/gession/internal/sessiontemplate"
+	"github.com/verte-zerg/gession/internal/sessiontree"
+)
+
+// newListedTUI returns a TUI with the live sessions and the folders listed, no tmux is needed until a session is switched to.
+func newListedTUI(t *testing.T, kind Kind, liveSessions, folders []*session.Session) *TUI {
+	t.Helper()
+
+	stateDir := t.TempDir()
+
+	tui := NewTUI(80, 24, kind, t.TempDir(), t.TempDir(), t.TempDir(), filepath.Join(stateDir, "trusted.json"), filepath.Join(stateDir, "history.jsonl"))
+	tui.SetOutputCh(make(chan event.Event, event.MaxQueue))
+	tui.loadHistory()
+	tui.loadTrusted()
+
+	tui.handleListedTree(liveSessions)
+	tui.handleListedFolders(event.ListedFolders{Sessions: folders, IsComplete: true})
+
+	return tui
+}
+
+func TestCreateTakenSessionName(t *testing.T) {
+	t.Parallel()
+
+	liveSessions := []*session.Session{{ID: "$1", Name: "api", Directory: "/home/user"}}
+	folders := []*session.Session{{ID: "notexisted_/code/api", Name: "api", Directory: "/code/api"}}
+
+	tui := newListedTUI(t, PrimeKind, liveSessions, folders)
+
+	selectedSession := tui.vTree.GetSelectedSession()
+	if selectedSession == nil || selectedSession.ID != "notexisted_/code/api" {
+		t.Fatalf("selected session = %+v, want the folder", selectedSession)
+	}
+
+	// The live session is started in another directory, so the folder isn't linked to it and a session would be created
+	tui.handleCommand("", false)
+
+	expected := "session api already exists in /home/user"
+	if tui.status != expected {
+		t.Errorf("status = %q, want %q", tui.status, expected)
+	}
+}
+
+func TestSwitchToTakenWorktreeSessionName(t *testing.T) {
+	t.Parallel()
+
+	liveSessions := []*session.Session{{ID: "$1", Name: worktreeSessionName("api", "feature"), Directory: "/home/user"}}
+	folders := []*session.Session{{
+		ID:        "notexisted_/code/api",
+		Name:      "api",
+		Directory: "/code/api",
+		Windows:   []session.Window{{ID: "/code/api-feature", Name: "feature", Directory: "/code/api-feature"}},
+	}}
+
+	tui := newListedTUI(t, PrimeKind, liveSessions, folders)
+	selectedWindow := &sessiontree.FilteredWindow{Window: &folders[0].Windows[0]}
+
+	// No live session is started in the worktree, so one would be created with the name of the live session
+	tui.handlePrimeCommand("", tui.vTree.GetSelectedSession(), selectedWindow)
+
+	expected := "session api@feature already exists in /home/user"
+	if tui.status != expected {
+		t.Errorf("status = %q, want %q", tui.status, expected)
+	}
+}
+
+func TestCreateTakenSessionNameCombined(t *testing.T) {
+	t.Parallel()
+
+	liveSessions := []*session.Session{{ID: "$1", Name: "api", Directory: "/home/user"}}
+	folders := []*session.Session{{ID: "notexisted_/code/api", Name: "api", Directory: "/code/api"}}
+
+	tui := newListedTUI(t, CombinedKind, liveSessions, folders)
+
+	// Folders are listed after the live sessions, the list grows up from the prompt
+	tui.selectedIdx++
+	tui.filterSessions()
+
+	selectedSession := tui.vTree.GetSelectedSession()
+	if selectedSession == nil || selectedSession.ID != "notexisted_/code/api" {
+		t.Fatalf("selected session = %+v, want the folder", selectedSession)
+	}
+
+	tui.handleCommand("", false)
+
+	expected := "session api already exists in /home/user"
+	if tui.status != expected {
+		t.Errorf("status = %q, want %q", tui.status, expected)
+	}
+}
+
+func TestWorktreeGitStatusError(t *testing.T) {
+	t.Parallel()
+
+	folders := []*session.Session{{ID: "notexisted_/code/api", Name: "api", Directory: "/code/api"}}
+
+	tui := newListedTUI(t, PrimeKind, []*session.Session{}, folders)
+	tui.handleCheckedGitStatus(event.CheckedGitStatus{
+		Statuses: map[string]*session.GitStatus{"/code/api": nil},
+		Errors:   map[string]string{"/code/api": "index file corrupt"},
+	})
+
+	tui.handleKeyEvent(event.KeyPressed{SpecialKey: key.CtrlW})
+
+	expected := "could not read git status: index file corrupt"
+	if tui.status != expected {
+		t.Errorf("status = %q, want %q", tui.status, expected)
+	}
+}
+
+func TestTreeRefreshGitStatusRequests(t *testing.T) {
+	t.Parallel()
+
+	liveSessions := []*session.Session{{ID: "$1", Name: "api", Directory: "/code/api"}}
+
+	tui := newListedTUI(t, NormalKind, liveSessions, nil)
+	tui.handleCheckedGitStatus(event.CheckedGitStatus{Statuses: map[string]*session.GitStatus{"/code/api": nil}})
+
+	// The tree is listed again with a new session, only its directory is checked
+	tui.handleListedTree(append(liveSessions, &session.Session{ID: "$2", Name: "web", Directory: "/code/web"}))
+
+	requested := make([][]string, 0)
+
+	for len(tui.eventOutputCh) > 0 {
+		if e := <-tui.eventOutputCh; e.Type == event.TypeCheckGitStatus {
+			requested = append(requested, e.Data.(event.CheckGitStatus).Directories)
+		}
+	}
+
+	expected := [][]string{{"/code/api"}, {"/code/web"}}
+	if !reflect.DeepEqual(requested, expected) {
+		t.Errorf("requested directories = %v, want %v", requested, expected)
+	}
+}
+
+func TestCreateWithUntrustedProjectTemplate(t *testing.T) {
+	t.Parallel()
+
+	projectDir := t.TempDir()
+	projectFile := filepath.Join(projectDir, sessiontemplate.ProjectFileName)
+
+	err := os.WriteFile(projectFile, []byte("windows:\n  - panes: [make deploy]\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	folders := []*session.Session{{ID: "notexisted_" + projectDir, Name: "api", Directory: projectDir}}
+
+	tui := newListedTUI(t, PrimeKind, []*session.Session{}, folders)
+
+	// The session isn't created until the template is trusted by pressing Enter again
+	tui.handleCommand("", false)
+
+	expected := projectFile + " is not trusted, press enter again to trust it and run its commands"
+	if tui.status != expected {
+		t.Errorf("status = %q, want %q", tui.status, expected)
+	}
+
+	if tui.pendingTrust == nil || tui.pendingTrust.Path != projectFile {
+		t.Errorf("pending trust = %+v, want %s", tui.pendingTrust, projectFile)
+	}
+}
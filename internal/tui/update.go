@@ -1,8 +1,14 @@
 package tui
 
 import (
+	"fmt"
+	"github.com/verte-zerg/gession/internal/event"
 	"github.com/verte-zerg/gession/internal/session"
 	"log/slog"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
 )
 
 func (tui *TUI) filterSessions() {
@@ -20,78 +26,294 @@ func (tui *TUI) filterSessions() {
 	}
 }
 
+// mergeSessionsAndPrimeSessions links prime sessions with live sessions started in the same directory.
+// Sessions with unknown directory are linked by name.
 func (tui *TUI) mergeSessionsAndPrimeSessions(primeSessions []*session.Session, normalSessions []*session.Session) {
 	logger.Info("merging sessions and prime sessions")
 
-	tui.sessions = primeSessions
+	tui.sessions = make([]*session.Session, 0, len(primeSessions))
 
 	tui.sessionIDToSession = make(map[string]*session.Session)
 
+	sessionDirectoryToSession := make(map[string]*session.Session)
 	sessionNameToSession := make(map[string]*session.Session)
+
 	for _, session := range normalSessions {
-		sessionNameToSession[session.Name] = session
+		if session.Directory == "" {
+			sessionNameToSession[session.Name] = session
+
+			continue
+		}
+
+		directory := tui.resolvePath(session.Directory)
+		if _, ok := sessionDirectoryToSession[directory]; !ok {
+			sessionDirectoryToSession[directory] = session
+		}
 	}
 
 	for _, primeSession := range primeSessions {
-		if normalSession, ok := sessionNameToSession[primeSession.Name]; ok {
+		// Prime sessions are copied, so they are kept intact between merges
+		mergedSession := *primeSession
+
+		normalSession, ok := sessionDirectoryToSession[tui.resolvePath(primeSession.Directory)]
+		if !ok {
+			normalSession, ok = sessionNameToSession[primeSession.Name]
+		}
+
+		if ok {
 			logger.Info("merging session", slog.String("primeSessionID", primeSession.ID), slog.String("normalSessionID", normalSession.ID))
-			primeSession.ID = normalSession.ID
+			mergedSession.ID = normalSession.ID
+			mergedSession.LinkedSession = normalSession
 		}
 
-		tui.sessionIDToSession[primeSession.ID] = primeSession
+		tui.sessions = append(tui.sessions, &mergedSession)
+		tui.sessionIDToSession[mergedSession.ID] = &mergedSession
 	}
+}
 
-	logger.Info("searching entities", slog.String("input", ""))
-	tui.vTree.SearchEntities("", tui.sessions, tui.selectedIdx, tui.unwrappedSession)
+// combineSessionsAndPrimeSessions lists the live sessions first and then the prime folders that have no session.
+// Folders are merged the same way as in prime mode, so a folder with a session started in it isn't listed twice.
+func (tui *TUI) combineSessionsAndPrimeSessions(primeSessions []*session.Session, normalSessions []*session.Session) {
+	tui.mergeSessionsAndPrimeSessions(primeSessions, normalSessions)
 
-	tui.Render()
+	folders := make([]*session.Session, 0, len(tui.sessions))
+
+	for _, folder := range tui.sessions {
+		if folder.LinkedSession == nil {
+			folders = append(folders, folder)
+		}
+	}
+
+	tui.sessions = append(slices.Clone(normalSessions), folders...)
+
+	// Merged folders are replaced by the sessions, so windows and panes are available for them
+	for _, session := range normalSessions {
+		tui.sessionIDToSession[session.ID] = session
+	}
 }
 
-func (tui *TUI) handleListedFolders(sessions []*session.Session) {
+// resolvePath returns the absolute path without symlinks, so the same directory always has the same path.
+func (tui *TUI) resolvePath(directory string) string {
+	if resolved, ok := tui.resolvedPaths[directory]; ok {
+		return resolved
+	}
+
+	resolved, err := filepath.EvalSymlinks(directory)
+	if err != nil {
+		resolved = filepath.Clean(directory)
+	}
+
+	tui.resolvedPaths[directory] = resolved
+
+	return resolved
+}
+
+// rebuildSessions recalculates the displayed sessions from the live and prime sessions, then refilters and renders them.
+func (tui *TUI) rebuildSessions() {
+	switch tui.kind {
+	case PrimeKind:
+		if !tui.isTreeListed || !tui.isPrimeListed {
+			return
+		}
+
+		tui.mergeSessionsAndPrimeSessions(tui.primeSessions, tui.liveSessions)
+	case CombinedKind:
+		if !tui.isTreeListed {
+			return
+		}
+
+		tui.combineSessionsAndPrimeSessions(tui.primeSessions, tui.liveSessions)
+	case NormalKind:
+		tui.sessions = slices.Clone(tui.liveSessions)
+		tui.sessionIDToSession = make(map[string]*session.Session)
+
+		for _, session := range tui.sessions {
+			tui.sessionIDToSession[session.ID] = session
+		}
+	}
+
 	if tui.kind != PrimeKind {
+		tui.sessions = append(tui.sessions, tui.hibernatedSessions...)
+
+		for _, session := range tui.hibernatedSessions {
+			tui.sessionIDToSession[session.ID] = session
+		}
+	}
+
+	for _, session := range tui.sessions {
+		session.GitStatus = tui.gitStatuses[session.Directory]
+
+		if tui.kind == PrimeKind {
+			session.Windows = worktreeWindows(session.GitStatus)
+		}
+	}
+
+	tui.paneIDToSession = make(map[string]*session.Session)
+
+	for _, session := range tui.liveSessions {
+		for _, window := range session.Windows {
+			for _, pane := range window.Panes {
+				tui.paneIDToSession[pane.ID] = session
+			}
+		}
+	}
+
+	tui.filterSessions()
+	tui.Render()
+}
+
+// worktreeWindows lists worktrees of a prime folder as its windows.
+func worktreeWindows(status *session.GitStatus) []session.Window {
+	if status == nil {
+		return nil
+	}
+
+	windows := make([]session.Window, 0, len(status.Worktrees))
+
+	for i, worktree := range status.Worktrees {
+		name := worktree.Branch
+		if worktree.IsDetached {
+			name = "detached@" + worktree.Commit
+		}
+
+		windows = append(windows, session.Window{
+			ID:        "worktree_" + worktree.Path,
+			Name:      name,
+			Index:     i,
+			Directory: worktree.Path,
+		})
+	}
+
+	return windows
+}
+
+// handleListedFolders adds or updates the folders by ID, the scanner sends them in batches while scanning.
+func (tui *TUI) handleListedFolders(listed event.ListedFolders) {
+	if tui.kind == NormalKind {
 		return
 	}
 
-	if tui.tmpSessions == nil {
-		tui.tmpSessions = sessions
-		logger.Info("tmp sessions", slog.Int("count", len(sessions)), slog.String("event_type", "listed_folders"))
+	logger.Info("listed folders", slog.Int("count", len(listed.Sessions)), slog.Bool("complete", listed.IsComplete))
+
+	for _, primeSession := range listed.Sessions {
+		tui.primeSessionIDToSession[primeSession.ID] = primeSession
+	}
+
+	tui.isPrimeListed = true
+	tui.requestGitStatus(listed.Sessions, false)
 
+	tui.sortPrimeSessions()
+	tui.rebuildSessions()
+}
+
+func (tui *TUI) handleRemovedFolders(removed event.RemovedFolders) {
+	if tui.kind == NormalKind {
 		return
 	}
 
-	tui.mergeSessionsAndPrimeSessions(sessions, tui.tmpSessions)
+	logger.Info("removed folders", slog.Int("count", len(removed.IDs)))
+
+	for _, id := range removed.IDs {
+		delete(tui.primeSessionIDToSession, id)
+	}
+
+	tui.sortPrimeSessions()
+	tui.rebuildSessions()
 }
 
-func (tui *TUI) handleListedTree(sessions []*session.Session) {
-	if tui.kind == PrimeKind {
-		if tui.tmpSessions == nil {
-			logger.Info("tmp sessions", slog.Int("count", len(sessions)), slog.String("event_type", "listed_tree"))
-			tui.tmpSessions = sessions
+// sortPrimeSessions rebuilds the prime sessions list from the folders by ID, the most frecent folders first.
+func (tui *TUI) sortPrimeSessions() {
+	tui.primeSessions = make([]*session.Session, 0, len(tui.primeSessionIDToSession))
+	scores := tui.history.FolderScores(time.Now())
 
-			return
+	for _, primeSession := range tui.primeSessionIDToSession {
+		tui.primeSessions = append(tui.primeSessions, primeSession)
+	}
+
+	sort.Slice(tui.primeSessions, func(i, j int) bool {
+		scoreI := scores[tui.resolvePath(tui.primeSessions[i].Directory)]
+		scoreJ := scores[tui.resolvePath(tui.primeSessions[j].Directory)]
+
+		if scoreI != scoreJ {
+			return scoreI > scoreJ
 		}
 
-		tui.mergeSessionsAndPrimeSessions(tui.tmpSessions, sessions)
+		return tui.primeSessions[i].Name > tui.primeSessions[j].Name
+	})
+}
 
+// sortLiveSessions orders the live sessions by frecency, sessions with the same score keep the order of the last attach.
+// The last attached session, usually the current one, stays at the end, so the previous session is selected by default.
+func (tui *TUI) sortLiveSessions() {
+	if len(tui.liveSessions) < 2 { //nolint:mnd
 		return
 	}
 
-	tui.sessions = sessions
+	lastAttachedIdx := 0
 
-	tui.paneIDToSession = make(map[string]*session.Session)
-	tui.sessionIDToSession = make(map[string]*session.Session)
+	for i, liveSession := range tui.liveSessions {
+		if liveSession.LastTimeAttached.After(tui.liveSessions[lastAttachedIdx].LastTimeAttached) {
+			lastAttachedIdx = i
+		}
+	}
+
+	lastAttached := tui.liveSessions[lastAttachedIdx]
+	scores := tui.history.SessionScores(time.Now())
+
+	sessions := slices.Delete(slices.Clone(tui.liveSessions), lastAttachedIdx, lastAttachedIdx+1)
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return scores[sessions[i].Name] > scores[sessions[j].Name]
+	})
+
+	tui.liveSessions = append(sessions, lastAttached)
+}
+
+func (tui *TUI) handleFoldersFailed(failed event.FoldersFailed) {
+	for _, folderError := range failed.Errors {
+		logger.Error("could not scan folder", slog.String("folder", folderError.Path), slog.String("error", folderError.Message))
+	}
+
+	if len(failed.Errors) == 0 {
+		return
+	}
+
+	tui.status = fmt.Sprintf("could not scan %d folder(s): %s", len(failed.Errors), failed.Errors[0].Message)
+	tui.Render()
+}
+
+func (tui *TUI) handleListedTree(sessions []*session.Session) {
+	tui.isTreeRequested = false
+
+	if tui.isTreeRefreshDirty {
+		tui.isTreeRefreshDirty = false
+		tui.requestTree()
+	}
+
+	snapshots := make(map[string]string)
+
+	for _, session := range tui.liveSessions {
+		for _, window := range session.Windows {
+			for _, pane := range window.Panes {
+				if pane.Snapshot != nil && *pane.Snapshot != "" {
+					snapshots[pane.ID] = *pane.Snapshot
+				}
+			}
+		}
+	}
 
 	for _, session := range sessions {
 		logger.Info("session", slog.String("id", session.ID), slog.Bool("attached", session.IsAttached))
 
-		tui.sessionIDToSession[session.ID] = session
-
 		for j := range session.Windows {
 			window := &session.Windows[j]
 
 			for k := range window.Panes {
 				pane := &window.Panes[k]
-				tui.paneIDToSession[pane.ID] = session
+
+				// Keep previously captured snapshots, so the preview doesn't blink on every tree update
+				if snapshot, ok := snapshots[pane.ID]; ok {
+					pane.Snapshot = &snapshot
+				}
 
 				logger.Info(
 					"list tree",
@@ -109,11 +331,69 @@ func (tui *TUI) handleListedTree(sessions []*session.Session) {
 		}
 	}
 
-	logger.Info("searching entities", slog.String("input", ""))
-	tui.vTree.SearchEntities("", tui.sessions, tui.selectedIdx, tui.unwrappedSession)
-	tui.requestSessionPreview(sessions[0].ID)
+	// The tree is listed again on every tmux change, only the first listing rechecks statuses, later ones check new directories
+	isFirstListing := !tui.isTreeListed
 
-	tui.Render()
+	tui.liveSessions = sessions
+	tui.sortLiveSessions()
+	tui.isTreeListed = true
+	tui.requestGitStatus(sessions, isFirstListing)
+
+	tui.rebuildSessions()
+}
+
+func (tui *TUI) handleSessionRenamed(sessionID, name string) {
+	for _, session := range tui.liveSessions {
+		if session.ID == sessionID {
+			session.Name = name
+		}
+	}
+
+	tui.rebuildSessions()
+}
+
+func (tui *TUI) handleWindowRenamed(windowID, name string) {
+	for _, session := range tui.liveSessions {
+		for i := range session.Windows {
+			if session.Windows[i].ID == windowID {
+				session.Windows[i].Name = name
+			}
+		}
+	}
+
+	tui.rebuildSessions()
+}
+
+func (tui *TUI) handleWindowClosed(windowID string) {
+	for _, liveSession := range tui.liveSessions {
+		windows := make([]session.Window, 0, len(liveSession.Windows))
+
+		for _, window := range liveSession.Windows {
+			if window.ID != windowID {
+				windows = append(windows, window)
+			}
+		}
+
+		liveSession.Windows = windows
+	}
+
+	tui.rebuildSessions()
+}
+
+func (tui *TUI) handleCheckedGitStatus(checked event.CheckedGitStatus) {
+	logger.Info("checked git status", slog.Int("count", len(checked.Statuses)), slog.Int("errors", len(checked.Errors)))
+
+	for directory, status := range checked.Statuses {
+		tui.gitStatuses[directory] = status
+
+		if message, ok := checked.Errors[directory]; ok {
+			tui.gitErrors[directory] = message
+		} else {
+			delete(tui.gitErrors, directory)
+		}
+	}
+
+	tui.rebuildSessions()
 }
 
 func (tui *TUI) handleCapturedPane(paneID, snapshot string) {
@@ -125,3 +405,34 @@ func (tui *TUI) handleCapturedPane(paneID, snapshot string) {
 		}
 	}
 }
+
+func (tui *TUI) handleCapturedPaneHistory(paneID, content string) {
+	pane, ok := tui.capturingPanes[paneID]
+	if !ok {
+		return
+	}
+
+	delete(tui.capturingPanes, paneID)
+	tui.contentIndex.Add(pane, content)
+
+	if tui.mode == searchMode {
+		tui.searchContent()
+		tui.Render()
+	}
+}
+
+func (tui *TUI) handleCommandFailed(failure event.CommandFailed) {
+	logger.Error("tmux command failed", slog.String("command", failure.Command), slog.String("error", failure.Message))
+
+	if failure.Request == event.TypeListTree {
+		tui.isTreeRequested = false
+
+		if tui.isTreeRefreshDirty {
+			tui.isTreeRefreshDirty = false
+			tui.requestTree()
+		}
+	}
+
+	tui.status = failure.Message
+	tui.Render()
+}
@@ -4,7 +4,6 @@ import (
 	"github.com/verte-zerg/gession/internal/event"
 	"github.com/verte-zerg/gession/internal/key"
 	"log/slog"
-	"os"
 )
 
 func (tui *TUI) deleteChar() {
@@ -29,6 +28,12 @@ func (tui *TUI) addChar(char rune) {
 }
 
 func (tui *TUI) handleMoving(keyEvent event.KeyPressed) (refilteringRequired bool) {
+	if tui.mode == searchMode {
+		tui.moveHitSelection(keyEvent)
+
+		return false
+	}
+
 	if tui.mode != normalMode {
 		return false
 	}
@@ -46,10 +51,6 @@ func (tui *TUI) handleMoving(keyEvent event.KeyPressed) (refilteringRequired boo
 
 		return true
 	case key.Left:
-		if tui.kind == PrimeKind {
-			return false
-		}
-
 		selectedSession := tui.vTree.GetSelectedSession()
 		if selectedSession == nil {
 			return false
@@ -65,10 +66,6 @@ func (tui *TUI) handleMoving(keyEvent event.KeyPressed) (refilteringRequired boo
 
 		return true
 	case key.Right:
-		if tui.kind == PrimeKind {
-			return false
-		}
-
 		selectedSession := tui.vTree.GetSelectedSession()
 		if selectedSession == nil {
 			return false
@@ -88,6 +85,17 @@ func (tui *TUI) handleMoving(keyEvent event.KeyPressed) (refilteringRequired boo
 	return false
 }
 
+// moveHitSelection moves the selection of the content search, hits are listed from the bottom like sessions.
+func (tui *TUI) moveHitSelection(keyEvent event.KeyPressed) {
+	//nolint:exhaustive
+	switch keyEvent.SpecialKey {
+	case key.Up, key.ShiftTab:
+		tui.selectedHitIdx = min(tui.selectedHitIdx+1, max(0, len(tui.hits)-1))
+	case key.Down, key.Tab:
+		tui.selectedHitIdx = max(tui.selectedHitIdx-1, 0)
+	}
+}
+
 //nolint:gocritic,cyclop,funlen
 func (tui *TUI) handleKeyEvent(keyEvent event.KeyPressed) {
 	logger.Info("key event", slog.String("key", string(keyEvent.Key)), slog.String("specialKey", string(keyEvent.SpecialKey)))
@@ -98,6 +106,9 @@ func (tui *TUI) handleKeyEvent(keyEvent event.KeyPressed) {
 
 	defer tui.Render()
 
+	// Status is shown until the next key press
+	tui.status = ""
+
 	refilteringRequired := false
 
 	defer func() {
@@ -110,14 +121,12 @@ func (tui *TUI) handleKeyEvent(keyEvent event.KeyPressed) {
 	switch keyEvent.SpecialKey {
 	// Exit on Ctrl+D, Ctrl+C
 	case key.EOT, key.ETX:
-		logger.Info("Exiting application")
-		os.Exit(0)
+		tui.exit(0)
 
 	// Reset mode to NORMAL, in NORMAL mode exit on Esc
 	case key.Esc:
 		if tui.mode == normalMode {
-			logger.Info("Exiting application")
-			os.Exit(0)
+			tui.exit(0)
 		} else {
 			ms := tui.modeStates[tui.mode]
 			ms.reset()
@@ -133,9 +142,14 @@ func (tui *TUI) handleKeyEvent(keyEvent event.KeyPressed) {
 			refilteringRequired = true
 		}
 
+		if tui.mode == searchMode {
+			tui.selectedHitIdx = 0
+			tui.searchContent()
+		}
+
 	// Enter to NEW mode
 	case key.CtrlT:
-		if tui.kind == NormalKind {
+		if tui.kind != PrimeKind && tui.mode != searchMode {
 			tui.mode = newMode
 
 			logger.Info("Switched to new mode")
@@ -149,6 +163,11 @@ func (tui *TUI) handleKeyEvent(keyEvent event.KeyPressed) {
 			refilteringRequired = true
 		}
 
+		if tui.mode == searchMode {
+			tui.selectedHitIdx = 0
+			tui.searchContent()
+		}
+
 	// Moving in menu
 	case key.Up, key.Down, key.Left, key.Right, key.Tab, key.ShiftTab:
 		if tui.handleMoving(keyEvent) {
@@ -169,13 +188,13 @@ func (tui *TUI) handleKeyEvent(keyEvent event.KeyPressed) {
 
 	// RENAME mode
 	case key.CtrlR:
-		if tui.kind == PrimeKind {
+		if tui.kind == PrimeKind || tui.mode != normalMode {
 			return
 		}
 
 		selectedSession := tui.vTree.GetSelectedSession()
 
-		if selectedSession == nil {
+		if selectedSession == nil || isFolderID(selectedSession.ID) || isHibernatedID(selectedSession.ID) {
 			return
 		}
 
@@ -191,16 +210,74 @@ func (tui *TUI) handleKeyEvent(keyEvent event.KeyPressed) {
 
 	// Delete session or window
 	case key.CtrlE:
-		if tui.kind == PrimeKind {
+		if tui.kind == PrimeKind || tui.mode != normalMode {
 			return
 		}
 
 		selectedSession := tui.vTree.GetSelectedSession()
-		if selectedSession == nil {
+		if selectedSession == nil || isFolderID(selectedSession.ID) {
 			return
 		}
 
 		tui.handleCommand("", true)
 		refilteringRequired = true
+
+	// Hibernate session
+	case key.CtrlZ:
+		if tui.kind == PrimeKind || tui.mode != normalMode {
+			return
+		}
+
+		selectedSession := tui.vTree.GetSelectedSession()
+		if selectedSession == nil || isFolderID(selectedSession.ID) || isHibernatedID(selectedSession.ID) {
+			return
+		}
+
+		tui.hibernateSession(selectedSession.ID)
+		refilteringRequired = true
+
+	// SEARCH mode
+	case key.CtrlF:
+		if tui.kind == PrimeKind || tui.mode != normalMode {
+			return
+		}
+
+		tui.startContentSearch()
+
+	// WORKTREE mode
+	case key.CtrlW:
+		if tui.kind != PrimeKind {
+			return
+		}
+
+		selectedSession := tui.vTree.GetSelectedSession()
+		if selectedSession == nil {
+			return
+		}
+
+		// Statuses are checked in the background, a folder without one isn't known to be a repository or not yet
+		if _, ok := tui.gitStatuses[selectedSession.Directory]; !ok {
+			tui.status = "git status is loading, try again in a moment: " + selectedSession.Directory
+
+			return
+		}
+
+		if message, ok := tui.gitErrors[selectedSession.Directory]; ok {
+			tui.status = "could not read git status: " + message
+
+			return
+		}
+
+		if selectedSession.GitStatus == nil {
+			tui.status = "not a git repository: " + selectedSession.Directory
+
+			return
+		}
+
+		tui.mode = worktreeMode
+		ms := tui.modeStates[worktreeMode]
+
+		placeholder := selectedSession.Name
+		ms.setPlaceholder(&placeholder)
 	}
 }
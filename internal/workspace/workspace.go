@@ -0,0 +1,282 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/verte-zerg/gession/internal/session"
+	"github.com/verte-zerg/gession/internal/sessiontemplate"
+	"github.com/verte-zerg/gession/internal/tmux"
+	"github.com/verte-zerg/gession/pkg/logging"
+)
+
+const (
+	// Version is the format version of the workspace file, files of other versions aren't restored.
+	Version = 1
+
+	dirMode = 0o755
+)
+
+var (
+	logger = logging.GetInstance().WithGroup("workspace")
+
+	// DefaultRestoreCommands are programs restarted on restore, other commands could have side effects if run again.
+	DefaultRestoreCommands = []string{"vi", "vim", "nvim", "emacs", "nano", "hx", "less", "man", "htop", "btop", "top", "tail", "watch"}
+
+	ErrNoSessions = errors.New("no tmux sessions to save")
+)
+
+// Workspace is a saved state of tmux sessions, every session is stored as a template it's recreated from.
+type Workspace struct {
+	Version  int       `json:"version"`
+	SavedAt  time.Time `json:"savedAt"`
+	Sessions []Session `json:"sessions"`
+}
+
+type Session struct {
+	Name      string                   `json:"name"`
+	Directory string                   `json:"directory"`
+	Template  sessiontemplate.Template `json:"template"`
+}
+
+// FromSessions converts the session tree to a workspace.
+// Commands are command lines of the processes running in panes by the pane process ID, panes without one run only a shell.
+func FromSessions(sessions []*session.Session, commands map[int]string) Workspace {
+	workspace := Workspace{
+		Version:  Version,
+		SavedAt:  time.Now(),
+		Sessions: make([]Session, 0, len(sessions)),
+	}
+
+	for _, liveSession := range sessions {
+		template := sessiontemplate.Template{
+			Windows: make([]sessiontemplate.Window, 0, len(liveSession.Windows)),
+		}
+
+		for _, window := range liveSession.Windows {
+			templateWindow := sessiontemplate.Window{
+				Name:   window.Name,
+				Layout: window.Layout,
+				Focus:  window.IsActive,
+				Panes:  make([]sessiontemplate.Pane, 0, len(window.Panes)),
+			}
+
+			for _, pane := range window.Panes {
+				templateWindow.Panes = append(templateWindow.Panes, sessiontemplate.Pane{
+					Command:   commands[pane.PID],
+					Directory: pane.CurrentPath,
+					Focus:     pane.IsActive,
+				})
+			}
+
+			template.Windows = append(template.Windows, templateWindow)
+		}
+
+		workspace.Sessions = append(workspace.Sessions, Session{
+			Name:      liveSession.Name,
+			Directory: liveSession.Directory,
+			Template:  template,
+		})
+	}
+
+	return workspace
+}
+
+// Capture returns the workspace of the running tmux server.
+func Capture() (Workspace, error) {
+	sessions, err := tmux.ListSessions()
+	if err != nil {
+		return Workspace{}, err
+	}
+
+	if len(sessions) == 0 {
+		return Workspace{}, ErrNoSessions
+	}
+
+	commands, err := ChildCommands()
+	if err != nil {
+		logger.Warn("could not list pane commands, only shells are saved", slog.Any("error", err))
+	}
+
+	return FromSessions(sessions, commands), nil
+}
+
+// ChildCommands returns the command line of a child process by the parent process ID.
+// Pane processes are shells, so their children are the commands run in the panes.
+// Forked subshells, e.g. running a prompt command, have the same command line as the shell and are skipped.
+func ChildCommands() (map[int]string, error) {
+	output, err := exec.Command("ps", "-A", "-o", "pid=,ppid=,args=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not list processes: %w", err)
+	}
+
+	type process struct {
+		parentPID int
+		args      string
+	}
+
+	processes := make(map[int]process)
+	order := make([]int, 0)
+
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 { //nolint:mnd
+			continue
+		}
+
+		pid, pidErr := strconv.Atoi(fields[0])
+		parentPID, parentErr := strconv.Atoi(fields[1])
+
+		if pidErr != nil || parentErr != nil {
+			continue
+		}
+
+		processes[pid] = process{parentPID: parentPID, args: strings.Join(fields[2:], " ")}
+		order = append(order, pid)
+	}
+
+	commands := make(map[int]string)
+
+	for _, pid := range order {
+		child := processes[pid]
+		if _, ok := commands[child.parentPID]; ok || child.args == processes[child.parentPID].args {
+			continue
+		}
+
+		commands[child.parentPID] = child.args
+	}
+
+	return commands, nil
+}
+
+// Save writes the workspace to the file, replacing it atomically.
+func Save(workspacePath string, workspace Workspace) error {
+	data, err := json.MarshalIndent(workspace, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode workspace: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(workspacePath), dirMode)
+	if err != nil {
+		return fmt.Errorf("could not create workspace directory: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(workspacePath), filepath.Base(workspacePath)+".*")
+	if err != nil {
+		return fmt.Errorf("could not create workspace file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not write workspace file: %w", err)
+	}
+
+	err = os.Rename(tmpFile.Name(), workspacePath)
+	if err != nil {
+		return fmt.Errorf("could not replace workspace file: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads the workspace from the file.
+func Load(workspacePath string) (Workspace, error) {
+	data, err := os.ReadFile(workspacePath)
+	if err != nil {
+		return Workspace{}, fmt.Errorf("could not read workspace: %w", err)
+	}
+
+	workspace := Workspace{}
+
+	err = json.Unmarshal(data, &workspace)
+	if err != nil {
+		return Workspace{}, fmt.Errorf("could not parse workspace: %w", err)
+	}
+
+	if workspace.Version != Version {
+		return Workspace{}, fmt.Errorf("unsupported workspace version %d, expected %d", workspace.Version, Version)
+	}
+
+	return workspace, nil
+}
+
+// FilterCommands drops pane commands that aren't allowed, the panes are restored with a shell only.
+func (w *Workspace) FilterCommands(isAllowed func(command string) bool) {
+	for i := range w.Sessions {
+		for _, command := range w.Sessions[i].Template.FilterCommands(isAllowed) {
+			logger.Info("skip command", slog.String("session", w.Sessions[i].Name), slog.String("command", command))
+		}
+	}
+}
+
+// AllowPrograms returns a filter allowing commands run with one of the programs.
+func AllowPrograms(programs []string) func(command string) bool {
+	allowed := make(map[string]struct{}, len(programs))
+	for _, program := range programs {
+		allowed[program] = struct{}{}
+	}
+
+	return func(command string) bool {
+		fields := strings.Fields(command)
+		if len(fields) == 0 {
+			return false
+		}
+
+		// Login shells and some programs prefix their names with a dash
+		_, ok := allowed[strings.TrimPrefix(filepath.Base(fields[0]), "-")]
+
+		return ok
+	}
+}
+
+// Restore creates the saved sessions, sessions with names that already exist are skipped.
+// It returns names of the restored and skipped sessions.
+func Restore(workspace Workspace) ([]string, []string, error) {
+	existing := make(map[string]struct{})
+
+	if tmux.HasSessions() {
+		sessions, err := tmux.ListSessions()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, liveSession := range sessions {
+			existing[liveSession.Name] = struct{}{}
+		}
+	}
+
+	restored := make([]string, 0, len(workspace.Sessions))
+	skipped := make([]string, 0)
+
+	for _, savedSession := range workspace.Sessions {
+		if _, ok := existing[savedSession.Name]; ok {
+			skipped = append(skipped, savedSession.Name)
+
+			continue
+		}
+
+		template := savedSession.Template
+
+		err := tmux.CreateTemplatedSession(savedSession.Name, savedSession.Directory, &template)
+		if err != nil {
+			return restored, skipped, fmt.Errorf("could not restore session %s: %w", savedSession.Name, err)
+		}
+
+		restored = append(restored, savedSession.Name)
+	}
+
+	return restored, skipped, nil
+}
@@ -0,0 +1,97 @@
+package workspace_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/verte-zerg/gession/internal/session"
+	"github.com/verte-zerg/gession/internal/sessiontemplate"
+	"github.com/verte-zerg/gession/internal/workspace"
+)
+
+func TestFromSessions(t *testing.T) {
+	sessions := []*session.Session{
+		{
+			Name:      "api",
+			Directory: "/code/api",
+			Windows: []session.Window{
+				{
+					Name:     "editor",
+					Layout:   "1a21,120x40,0,0{60x40,0,0,11,59x40,61,0,12}",
+					IsActive: true,
+					Panes: []session.Pane{
+						{PID: 100, CurrentPath: "/code/api"},
+						{PID: 200, CurrentPath: "/code/api/web", IsActive: true},
+					},
+				},
+			},
+		},
+	}
+
+	saved := workspace.FromSessions(sessions, map[int]string{200: "nvim main.go"})
+	saved.FilterCommands(workspace.AllowPrograms(workspace.DefaultRestoreCommands))
+
+	expected := []workspace.Session{
+		{
+			Name:      "api",
+			Directory: "/code/api",
+			Template: sessiontemplate.Template{
+				Windows: []sessiontemplate.Window{
+					{
+						Name:   "editor",
+						Layout: "1a21,120x40,0,0{60x40,0,0,11,59x40,61,0,12}",
+						Focus:  true,
+						Panes: []sessiontemplate.Pane{
+							{Directory: "/code/api"},
+							{Directory: "/code/api/web", Command: "nvim main.go", Focus: true},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(saved.Sessions, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, saved.Sessions)
+	}
+
+	workspacePath := filepath.Join(t.TempDir(), "gession", "workspace.json")
+
+	err := workspace.Save(workspacePath, saved)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	loaded, err := workspace.Load(workspacePath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(loaded.Sessions, saved.Sessions) {
+		t.Errorf("Expected %+v, got %+v", saved.Sessions, loaded.Sessions)
+	}
+}
+
+func TestAllowPrograms(t *testing.T) {
+	isAllowed := workspace.AllowPrograms([]string{"nvim", "less"})
+
+	tests := []struct {
+		command  string
+		expected bool
+	}{
+		{command: "nvim main.go", expected: true},
+		{command: "/usr/bin/less -R log.txt", expected: true},
+		{command: "rm -rf build", expected: false},
+		{command: "-bash", expected: false},
+		{command: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if isAllowed(tt.command) != tt.expected {
+				t.Errorf("Expected %v for %q", tt.expected, tt.command)
+			}
+		})
+	}
+}
@@ -1,27 +1,283 @@
 package fuzzy
 
+import (
+	"math"
+	"slices"
+	"unicode"
+)
+
+// Scores follow fzf: a match is worth more at word boundaries and in consecutive runs, gaps between matches are penalized.
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	// bonusBoundary is given to a word char after a non-word char, e.g. `b` in `a-b`.
+	bonusBoundary = scoreMatch / 2
+	// bonusBoundaryWhite and bonusBoundaryDelimiter are boundaries after whitespace and path separators,
+	// they start a new name, so they are worth more than other boundaries.
+	bonusBoundaryWhite     = bonusBoundary + 2
+	bonusBoundaryDelimiter = bonusBoundary + 1
+	bonusNonWord           = scoreMatch / 2
+	// bonusCamel123 is given to an upper case letter after a lower case one and to the first digit, e.g. `B` in `aB`.
+	bonusCamel123 = bonusBoundary + scoreGapExtension
+	// bonusConsecutive makes a run of matches beat the same matches with a gap between them.
+	bonusConsecutive = -(scoreGapStart + scoreGapExtension)
+	// bonusFirstCharMultiplier emphasizes where the match starts.
+	bonusFirstCharMultiplier = 2
+
+	noScore = math.MinInt32
+)
+
+type charClass int
+
+const (
+	charWhite charClass = iota
+	charNonWord
+	charDelimiter
+	charLower
+	charUpper
+	charLetter
+	charNumber
+)
+
 type ColorizedText struct {
 	Highlighted bool
 	Text        string
 }
 
-func Search(s string, query string) bool {
-	sRune := []rune(s)
-	queryRune := []rune(query)
+// Result is a match of the query in the text.
+type Result struct {
+	Score int
+	// Positions are indexes of the matched runes in the text, in ascending order.
+	Positions []int
+}
+
+// Match finds the best match of the query chars in the text, in order but not necessarily adjacent.
+// The search is case-insensitive unless the query has an upper case letter.
+// An empty query matches any text with a zero score.
+func Match(s string, query string) (Result, bool) {
+	text, pattern, bonuses := prepare(s, query)
+
+	if len(pattern) == 0 {
+		return Result{}, true
+	}
+
+	if len(text) < len(pattern) || !isSubsequence(text, pattern) {
+		return Result{}, false
+	}
+
+	return align(text, pattern, bonuses), true
+}
+
+// MatchExact finds the best occurrence of the query in the text, the case is matched like in Match.
+func MatchExact(s string, query string) (Result, bool) {
+	text, pattern, bonuses := prepare(s, query)
+
+	best := Result{Score: noScore}
+
+	for start := 0; start+len(pattern) <= len(text); start++ {
+		if !slices.Equal(text[start:start+len(pattern)], pattern) {
+			continue
+		}
+
+		if score := scoreRun(bonuses, start, len(pattern)); score > best.Score {
+			best = Result{Score: score, Positions: runPositions(start, len(pattern))}
+		}
+	}
+
+	return best, best.Score != noScore
+}
+
+// MatchPrefix matches the text starting with the query.
+func MatchPrefix(s string, query string) (Result, bool) {
+	text, pattern, bonuses := prepare(s, query)
+
+	if len(text) < len(pattern) || !slices.Equal(text[:len(pattern)], pattern) {
+		return Result{}, false
+	}
+
+	return Result{Score: scoreRun(bonuses, 0, len(pattern)), Positions: runPositions(0, len(pattern))}, true
+}
+
+// MatchSuffix matches the text ending with the query.
+func MatchSuffix(s string, query string) (Result, bool) {
+	text, pattern, bonuses := prepare(s, query)
+
+	start := len(text) - len(pattern)
+	if start < 0 || !slices.Equal(text[start:], pattern) {
+		return Result{}, false
+	}
+
+	return Result{Score: scoreRun(bonuses, start, len(pattern)), Positions: runPositions(start, len(pattern))}, true
+}
+
+// MatchEqual matches the text equal to the query.
+func MatchEqual(s string, query string) (Result, bool) {
+	text, pattern, bonuses := prepare(s, query)
+
+	if !slices.Equal(text, pattern) {
+		return Result{}, false
+	}
+
+	return Result{Score: scoreRun(bonuses, 0, len(pattern)), Positions: runPositions(0, len(pattern))}, true
+}
+
+// prepare returns the text and query normalized for comparison and the bonuses of the text chars.
+// The case is ignored unless the query has an upper case letter.
+func prepare(s string, query string) ([]rune, []rune, []int) {
+	text := []rune(s)
+	pattern := []rune(query)
+
+	caseSensitive := hasUpper(pattern)
+	bonuses := make([]int, len(text))
+	prevClass := charWhite
+
+	for i, char := range text {
+		class := classOf(char)
+		bonuses[i] = bonusFor(prevClass, class)
+		prevClass = class
+
+		if !caseSensitive {
+			text[i] = unicode.ToLower(char)
+		}
+	}
+
+	if !caseSensitive {
+		for i, char := range pattern {
+			pattern[i] = unicode.ToLower(char)
+		}
+	}
+
+	return text, pattern, bonuses
+}
+
+// scoreRun scores the consecutive match of the length at the start, the same way align scores runs.
+func scoreRun(bonuses []int, start, length int) int {
+	if length == 0 {
+		return 0
+	}
+
+	score := scoreMatch + bonuses[start]*bonusFirstCharMultiplier
+	runStart := start
+
+	for j := start + 1; j < start+length; j++ {
+		bonus := bonuses[j]
+
+		if bonus >= bonusBoundary && bonus > bonuses[runStart] {
+			runStart = j
+		} else {
+			bonus = max(bonus, bonusConsecutive, bonuses[runStart])
+		}
+
+		score += scoreMatch + bonus
+	}
+
+	return score
+}
 
-	if len(queryRune) == 0 {
-		return true
+func runPositions(start, length int) []int {
+	positions := make([]int, 0, length)
+	for i := start; i < start+length; i++ {
+		positions = append(positions, i)
 	}
 
-	if len(sRune) == 0 {
-		return false
+	return positions
+}
+
+// align scores every placement of the query chars and returns the best one.
+// scores[i][j] is the best score of the first i+1 query chars with the last one matched at text[j].
+func align(text, query []rune, bonuses []int) Result {
+	scores := make([][]int, len(query))
+	runs := make([][]int, len(query))
+	from := make([][]int, len(query))
+
+	for i := range query {
+		scores[i] = make([]int, len(text))
+		runs[i] = make([]int, len(text))
+		from[i] = make([]int, len(text))
+
+		// gapScore is the best score of the previous query char matched before j-1, with the gap up to j penalized
+		gapScore, gapFrom := noScore, -1
+
+		for j := range text {
+			scores[i][j] = noScore
+
+			if i > 0 && j > 1 {
+				if gapScore != noScore {
+					gapScore += scoreGapExtension
+				}
+
+				if previous := scores[i-1][j-2]; previous != noScore && previous+scoreGapStart > gapScore {
+					gapScore, gapFrom = previous+scoreGapStart, j-2
+				}
+			}
+
+			if text[j] != query[i] {
+				continue
+			}
+
+			if i == 0 {
+				scores[i][j] = scoreMatch + bonuses[j]*bonusFirstCharMultiplier
+				runs[i][j] = 1
+				from[i][j] = -1
+
+				continue
+			}
+
+			if gapScore != noScore {
+				scores[i][j] = gapScore + scoreMatch + bonuses[j]
+				runs[i][j] = 1
+				from[i][j] = gapFrom
+			}
+
+			if j == 0 || scores[i-1][j-1] == noScore {
+				continue
+			}
+
+			// A char in a run gets at least the bonus of the run start, unless it starts a new word itself
+			bonus := bonuses[j]
+			run := runs[i-1][j-1] + 1
+			runStartBonus := bonuses[j-run+1]
+
+			if bonus >= bonusBoundary && bonus > runStartBonus {
+				run = 1
+			} else {
+				bonus = max(bonus, bonusConsecutive, runStartBonus)
+			}
+
+			if consecutiveScore := scores[i-1][j-1] + scoreMatch + bonus; consecutiveScore >= scores[i][j] {
+				scores[i][j] = consecutiveScore
+				runs[i][j] = run
+				from[i][j] = j - 1
+			}
+		}
 	}
 
+	last := len(query) - 1
+	result := Result{Score: noScore, Positions: make([]int, len(query))}
+
+	for j, score := range scores[last] {
+		if score > result.Score {
+			result.Score = score
+			result.Positions[last] = j
+		}
+	}
+
+	for i := last; i > 0; i-- {
+		result.Positions[i-1] = from[i][result.Positions[i]]
+	}
+
+	return result
+}
+
+func isSubsequence(text, query []rune) bool {
 	queryIdx := 0
-	for i := range sRune {
-		if sRune[i] == queryRune[queryIdx] {
+
+	for _, char := range text {
+		if char == query[queryIdx] {
 			queryIdx++
-			if queryIdx == len(queryRune) {
+			if queryIdx == len(query) {
 				return true
 			}
 		}
@@ -30,35 +286,96 @@ func Search(s string, query string) bool {
 	return false
 }
 
-func SearchColorized(s string, query string) ([]ColorizedText, bool) {
-	results := make([]ColorizedText, 0)
+func hasUpper(chars []rune) bool {
+	for _, char := range chars {
+		if unicode.IsUpper(char) {
+			return true
+		}
+	}
 
-	sRune := []rune(s)
-	queryRune := []rune(query)
+	return false
+}
+
+func classOf(char rune) charClass {
+	switch {
+	case unicode.IsLower(char):
+		return charLower
+	case unicode.IsUpper(char):
+		return charUpper
+	case unicode.IsNumber(char):
+		return charNumber
+	case unicode.IsLetter(char):
+		return charLetter
+	case unicode.IsSpace(char):
+		return charWhite
+	case char == '/' || char == ',' || char == ':' || char == ';' || char == '|':
+		return charDelimiter
+	default:
+		return charNonWord
+	}
+}
+
+func bonusFor(prevClass, class charClass) int {
+	if class > charDelimiter {
+		switch prevClass {
+		case charWhite:
+			return bonusBoundaryWhite
+		case charDelimiter:
+			return bonusBoundaryDelimiter
+		case charNonWord:
+			return bonusBoundary
+		}
+	}
+
+	if prevClass == charLower && class == charUpper || prevClass != charNumber && class == charNumber {
+		return bonusCamel123
+	}
 
-	if len(queryRune) == 0 {
-		return []ColorizedText{{Highlighted: false, Text: string(sRune)}}, true
+	switch class {
+	case charNonWord, charDelimiter:
+		return bonusNonWord
+	case charWhite:
+		return bonusBoundaryWhite
 	}
 
-	if len(sRune) == 0 {
+	return 0
+}
+
+func Search(s string, query string) bool {
+	_, ok := Match(s, query)
+
+	return ok
+}
+
+// SearchColorized splits the text into highlighted matched chars and the rest.
+func SearchColorized(s string, query string) ([]ColorizedText, bool) {
+	result, ok := Match(s, query)
+	if !ok {
 		return []ColorizedText{}, false
 	}
 
-	queryIdx := 0
-	for runeIdx := range sRune {
-		if sRune[runeIdx] == queryRune[queryIdx] {
-			results = append(results, ColorizedText{Highlighted: true, Text: string(sRune[runeIdx])})
-			queryIdx++
+	return Colorize(s, result.Positions), true
+}
 
-			if queryIdx == len(queryRune) {
-				results = append(results, ColorizedText{Highlighted: false, Text: string(sRune[runeIdx+1:])})
+// Colorize splits the text into highlighted chars at the positions and the rest.
+func Colorize(s string, positions []int) []ColorizedText {
+	if len(positions) == 0 {
+		return []ColorizedText{{Highlighted: false, Text: s}}
+	}
 
-				return results, true
-			}
-		} else {
-			results = append(results, ColorizedText{Highlighted: false, Text: string(sRune[runeIdx])})
+	sRune := []rune(s)
+	lastPosition := positions[len(positions)-1]
+	results := make([]ColorizedText, 0, lastPosition+2) //nolint:mnd
+	positionIdx := 0
+
+	for runeIdx := 0; runeIdx <= lastPosition; runeIdx++ {
+		isMatched := positions[positionIdx] == runeIdx
+		if isMatched {
+			positionIdx++
 		}
+
+		results = append(results, ColorizedText{Highlighted: isMatched, Text: string(sRune[runeIdx])})
 	}
 
-	return results, false
+	return append(results, ColorizedText{Highlighted: false, Text: string(sRune[lastPosition+1:])})
 }
@@ -0,0 +1,108 @@
+package fuzzy_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/verte-zerg/gession/pkg/fuzzy"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		query     string
+		matched   bool
+		positions []int
+	}{
+		{
+			name:    "Empty query",
+			text:    "gession",
+			query:   "",
+			matched: true,
+		},
+		{
+			name:      "Subsequence",
+			text:      "gession",
+			query:     "gsn",
+			matched:   true,
+			positions: []int{0, 2, 6},
+		},
+		{
+			name:      "Lower case query ignores case",
+			text:      "MyAPI",
+			query:     "api",
+			matched:   true,
+			positions: []int{2, 3, 4},
+		},
+		{
+			name:    "Upper case query is case-sensitive",
+			text:    "my-api",
+			query:   "Api",
+			matched: false,
+		},
+		{
+			name:      "Consecutive run is preferred",
+			text:      "gession",
+			query:     "ss",
+			matched:   true,
+			positions: []int{2, 3},
+		},
+		{
+			name:      "Word boundaries are preferred over the first occurrence",
+			text:      "sabotage/a_b",
+			query:     "ab",
+			matched:   true,
+			positions: []int{9, 11},
+		},
+		{
+			name:      "Camel case humps",
+			text:      "handleListedFolders",
+			query:     "lf",
+			matched:   true,
+			positions: []int{6, 12},
+		},
+		{
+			name:    "Query longer than text",
+			text:    "go",
+			query:   "gon",
+			matched: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, matched := fuzzy.Match(tt.text, tt.query)
+			if matched != tt.matched {
+				t.Fatalf("Expected matched to be %v, got %v", tt.matched, matched)
+			}
+
+			if matched && !reflect.DeepEqual(result.Positions, tt.positions) {
+				t.Errorf("Expected positions %v, got %v", tt.positions, result.Positions)
+			}
+		})
+	}
+}
+
+func TestMatchRanking(t *testing.T) {
+	// Every text is expected to score higher than the next one
+	tests := []struct {
+		query string
+		texts []string
+	}{
+		{query: "api", texts: []string{"api", "work/api", "rapid"}},
+		{query: "gs", texts: []string{"go-server", "gessions", "big-cases"}},
+	}
+
+	for _, tt := range tests {
+		for i := 1; i < len(tt.texts); i++ {
+			better, _ := fuzzy.Match(tt.texts[i-1], tt.query)
+			worse, _ := fuzzy.Match(tt.texts[i], tt.query)
+
+			if better.Score <= worse.Score {
+				t.Errorf("Expected %q (%d) to score higher than %q (%d) for %q",
+					tt.texts[i-1], better.Score, tt.texts[i], worse.Score, tt.query)
+			}
+		}
+	}
+}